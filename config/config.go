@@ -7,12 +7,61 @@ import (
 
 // Config 定义所有信誉计算参数，可从 JSON 文件加载
 // 论文算法参数:
-// Gamma: 不确定性对信誉的影响系数 (默认0.5)
+// Gamma: 默认基础率 a, 即不确定性对信誉的影响系数 (默认0.5)
 // Rho1, Rho2: 整合权重 (交互频率、轨迹相似度), Rho1+Rho2=1
 // Zeta, Sigma: 时效性权重 (近期、过去), Zeta+Sigma=1, 推荐Zeta=0.7
 // Theta, Tau: 正负事件时效性衰减因子
 // Psi1, Psi2, Psi3: 轨迹相似度权重 (速度、位置、方向), Psi1+Psi2+Psi3=1
 // TRecent: 近期事件时间阈值 (秒)
+// IFNormalization: 交互频率 IF 的归一化模式 (ratio/minmax/softmax/capped), 默认 ratio 即论文公式4, 其余模式将 IF 限制在 [0,1]
+// IFCap: capped 模式下 IF 比值的上限, 默认 2
+// LocationThreshold: LCS 匹配的纵向位置阈值 (归一化), 默认 0.05
+// LateralThreshold: LCS 匹配的横向位置阈值 (米), 为 0 时只比较纵向位置
+// TrajectoryMetric: 公式8 位置差异使用的轨迹距离 (lcs/dtw/frechet/edr/hausdorff), 默认 lcs,
+//   距离以上述阈值为单位并归一化到 [0,1]
+// TrajectoryWindow: 轨迹相似度使用以当前时刻结束的时间窗口长度(秒), 需通过 RecordTrajectory 记录轨迹,
+//   窗口外的轨迹点被丢弃, 窗口内没有轨迹时轨迹相似度为 0; 0 表示使用首次交互时记录的轨迹
+// DecayMode: 证据时间衰减模式 (bucket/exponential), 默认 bucket 即近期/过去两段式
+// HalfLifePos, HalfLifeNeg: exponential 模式下正、负事件的半衰期 (秒)
+// LambdaPos, LambdaNeg: exponential 模式下正、负事件的衰减率, 非零时优先于半衰期
+// DirectionMode: 方向差异模式 (mean/aligned), 默认 mean 即比较圆周平均方向
+// FusionOperator: 本地与推荐意见的融合算子 (local/cumulative/averaging/weighted), 默认 local
+// TrustModel: 信任模型 (subjective/beta/eigentrust/peertrust/dirichlet), 默认 subjective 即论文模型
+// BetaForgetting: beta 模型的遗忘因子 λ ∈ (0,1], 默认 1 不遗忘
+// EigenTrustAlpha: eigentrust 模型中预信任向量的权重 a ∈ (0,1), 默认 0.15
+// CollusionDetection: 每轮交互后是否运行共谋/女巫检测
+// CollusionBoost: 共谋检测中团体内评价比团体外评价高出的幅度, 女巫检测中组内评价偏离组外多数评价的幅度, 默认 0.2
+// CollusionDensity: 共谋团体内互相抬高的边密度阈值, 默认 0.8
+// SybilTolerance, SybilMinCommon: 女巫检测的评价差异容忍度 (默认 0.01) 与最少共同目标数 (默认 3)
+// SybilTrajectory: 女巫检测要求的最低轨迹相似度, 默认 0.98
+// CollusionPenalty: 被标记推荐者的权重系数, 默认 0.1
+// RecFilter: 不诚实推荐过滤规则 (none/quantile/deviation), 默认 none
+// RecQuantile: quantile 规则的分位数 q, 默认 0.01
+// RecDeviation: deviation 规则允许的最大偏差, 默认 0.3
+// MaxHops: 推荐链最多经过的推荐者数量, 默认 1 即只使用邻居的直接证据
+// MaxFanout: 传递推荐时每一跳最多询问的邻居数量 (按信任值从高到低), 默认 3
+// SnapshotDir: 信誉状态快照目录, 非空时启动时恢复、结束时保存
+// PeerTransport: 邻居推荐的传输方式 (memory: 进程内直接调用, http: 经本机回环 HTTP 查询, 仅支持 subjective 模型)
+// RetentionMaxAge: 交互记录的最长保留时间(秒), 更早的记录被压缩为聚合证据, 0 表示不限制
+// RetentionMaxRecords: 每个交互对 (From, To) 最多保留的记录数, 0 表示不限制, 仅支持 exponential 衰减模式
+// ContextSimilarity: 计算某一数据类别的信誉时, 其他类别证据的混合权重 [0,1], 0 表示只使用该类别的证据
+// EventSeverity: 各事件类型的严重程度权重, 如 {"forged_data": 5, "delayed_data": 0.3, "dropped_request": 1},
+//   PosEvents/NegEvents 分别按 "positive"/"negative" 类型计, 未配置的类型使用默认值
+// NewcomerPolicy: 新节点引导策略 (none: 不区分, minimum: 考察期内使用 NewcomerBaseRate 且不高于已知节点的最低信誉)
+// NewcomerBaseRate: 考察期内代替 Gamma 的基础率, 陌生节点的信誉约等于该值
+// ProbationPeriod: 考察期长度(秒), 从本节点首次记录到该节点起计算
+// ProbationCap: 考察期内的信誉上限, 0 表示不限制
+// RedemptionRate: 宽恕模型中不良行为后每秒最多恢复的信誉, 0 表示不启用宽恕模型
+// SevereOffence: 单次交互的负面证据达到该值即为严重违规, 默认 5
+// MaxRecovery: 每次严重违规后可恢复的最高信誉乘以该比例, 默认 0.8
+// NodeClassBaseRate: 各节点类别的基础率, 如 {"rsu": 0.9, "bus": 0.7, "car": 0.5}, 未配置的类别使用 Gamma
+// RatingLevels: 多项意见的评价等级, 从好到坏排列, 默认 accurate/slightly_off/stale/fabricated
+// RatingUtility: 各评价等级的效用 [0,1], 用于将多项意见投影为标量信誉, 默认 1/0.7/0.3/0
+// ConfidenceLevel: 信誉可信区间的置信水平, 默认 0.95
+// SelectionPolicy: SelectOptimalProvider 的选择策略 (greedy/epsilon-greedy/ucb/thompson/lower-bound), 默认 greedy 即按信誉值
+// SelectionEpsilon: epsilon-greedy 策略的探索概率
+// SelectionUCBWeight: ucb 策略的探索系数 c, 默认 1
+// SelectionSeed: 随机策略的种子, 第 k 次调用 SelectOptimalProvider 使用 SelectionSeed+k, 结果可复现且每次抽样不同
 
 type Config struct {
 	Gamma   float64 `json:"gamma"`
@@ -26,6 +75,75 @@ type Config struct {
 	Psi2    float64 `json:"psi2"`
 	Psi3    float64 `json:"psi3"`
 	TRecent float64 `json:"t_recent"`
+
+	IFNormalization string  `json:"if_normalization"`
+	IFCap           float64 `json:"if_cap"`
+
+	LocationThreshold float64 `json:"location_threshold"`
+	LateralThreshold  float64 `json:"lateral_threshold"`
+	TrajectoryMetric  string  `json:"trajectory_metric"`
+	TrajectoryWindow  float64 `json:"trajectory_window"`
+
+	DecayMode   string  `json:"decay_mode"`
+	HalfLifePos float64 `json:"half_life_pos"`
+	HalfLifeNeg float64 `json:"half_life_neg"`
+	LambdaPos   float64 `json:"lambda_pos"`
+	LambdaNeg   float64 `json:"lambda_neg"`
+
+	DirectionMode string `json:"direction_mode"`
+
+	FusionOperator string `json:"fusion_operator"`
+
+	TrustModel      string  `json:"trust_model"`
+	BetaForgetting  float64 `json:"beta_forgetting"`
+	EigenTrustAlpha float64 `json:"eigentrust_alpha"`
+
+	CollusionDetection bool    `json:"collusion_detection"`
+	CollusionBoost     float64 `json:"collusion_boost"`
+	CollusionDensity   float64 `json:"collusion_density"`
+	SybilTolerance     float64 `json:"sybil_tolerance"`
+	SybilMinCommon     int     `json:"sybil_min_common"`
+	SybilTrajectory    float64 `json:"sybil_trajectory"`
+	CollusionPenalty   float64 `json:"collusion_penalty"`
+
+	RecFilter    string  `json:"rec_filter"`
+	RecQuantile  float64 `json:"rec_quantile"`
+	RecDeviation float64 `json:"rec_deviation"`
+
+	MaxHops   int `json:"max_hops"`
+	MaxFanout int `json:"max_fanout"`
+
+	SnapshotDir string `json:"snapshot_dir"`
+
+	PeerTransport string `json:"peer_transport"`
+
+	RetentionMaxAge     float64 `json:"retention_max_age"`
+	RetentionMaxRecords int     `json:"retention_max_records"`
+
+	ContextSimilarity float64 `json:"context_similarity"`
+
+	EventSeverity map[string]float64 `json:"event_severity"`
+
+	NewcomerPolicy   string  `json:"newcomer_policy"`
+	NewcomerBaseRate float64 `json:"newcomer_base_rate"`
+	ProbationPeriod  float64 `json:"probation_period"`
+	ProbationCap     float64 `json:"probation_cap"`
+
+	RedemptionRate float64 `json:"redemption_rate"`
+	SevereOffence  float64 `json:"severe_offence"`
+	MaxRecovery    float64 `json:"max_recovery"`
+
+	NodeClassBaseRate map[string]float64 `json:"node_class_base_rate"`
+
+	RatingLevels  []string  `json:"rating_levels"`
+	RatingUtility []float64 `json:"rating_utility"`
+
+	ConfidenceLevel float64 `json:"confidence_level"`
+	SelectionPolicy string  `json:"selection_policy"`
+
+	SelectionEpsilon   float64 `json:"selection_epsilon"`
+	SelectionUCBWeight float64 `json:"selection_ucb_weight"`
+	SelectionSeed      int64   `json:"selection_seed"`
 }
 
 // LoadConfig 从指定路径加载 JSON 配置
@@ -8,6 +8,8 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"sync"
@@ -109,6 +111,7 @@ type RawData struct {
 	Time      float64
 	X         float64
 	Y         float64
+	Lane      int // 车道编号，0 表示未知
 	Speed     float64
 }
 
@@ -116,6 +119,11 @@ const (
 	roadLength = 352.0 // 道路总长，单位：米
 )
 
+// snapshotPath 返回节点信誉快照的文件路径
+func snapshotPath(dir, vid string) string {
+	return filepath.Join(dir, "node_"+vid+".snap")
+}
+
 func main() {
 	startTime := time.Now()
 	rand.Seed(time.Now().UnixNano())
@@ -157,6 +165,17 @@ func main() {
 	logger.Printf("配置加载成功: rho1=%.2f, rho2=%.2f, rho3=%.2f, gamma=%.2f\n",
 		cfg.Rho1, cfg.Rho2, 1-cfg.Rho1-cfg.Rho2, cfg.Gamma)
 
+	// 按名称选择信任模型，并检查相互依赖的配置项
+	if cfg.TrustModel == "" {
+		cfg.TrustModel = reputation.ModelSubjectiveLogic
+	}
+	if err := reputation.ValidateConfig(cfg); err != nil {
+		fmt.Println("配置错误:", err)
+		logger.Println("ERROR: 配置错误:", err)
+		return
+	}
+	logger.Printf("信任模型: %s\n", cfg.TrustModel)
+
 	// 3. 读取 Excel 数据
 	f, err := excelize.OpenFile("data.xlsx")
 	if err != nil {
@@ -177,7 +196,8 @@ func main() {
 
 	// 解析表头索引
 	header := rows[0]
-	var iVID, iTime, iLong, iUp, iLow, iSpd int
+	var iVID, iTime, iLong, iSpd int
+	iUp, iLow, iLane := -1, -1, -1 // 车道线距离与车道编号列可选
 	for idx, title := range header {
 		switch title {
 		case "vehicleID":
@@ -192,6 +212,8 @@ func main() {
 			iLow = idx
 		case "speed(m/s)":
 			iSpd = idx
+		case "laneID":
+			iLane = idx
 		}
 	}
 
@@ -200,12 +222,20 @@ func main() {
 		vid := row[iVID]
 		t, _ := strconv.ParseFloat(row[iTime], 64)
 		lon, _ := strconv.ParseFloat(row[iLong], 64)
-		up, _ := strconv.ParseFloat(row[iUp], 64)
-		low, _ := strconv.ParseFloat(row[iLow], 64)
 		spd, _ := strconv.ParseFloat(row[iSpd], 64)
 		x := lon / roadLength
-		y := (up + low) / 2.0
-		dataMap[vid] = append(dataMap[vid], RawData{VehicleID: vid, Time: t, X: x, Y: y, Speed: spd})
+		var y float64 // 缺少车道线距离列时横向位置为 0
+		if iUp >= 0 && iUp < len(row) && iLow >= 0 && iLow < len(row) {
+			up, _ := strconv.ParseFloat(row[iUp], 64)
+			low, _ := strconv.ParseFloat(row[iLow], 64)
+			y = (up + low) / 2.0
+		}
+		var lane int
+		if iLane >= 0 && iLane < len(row) {
+			l, _ := strconv.ParseFloat(row[iLane], 64)
+			lane = int(l)
+		}
+		dataMap[vid] = append(dataMap[vid], RawData{VehicleID: vid, Time: t, X: x, Y: y, Lane: lane, Speed: spd})
 	}
 
 	for _, slice := range dataMap {
@@ -241,6 +271,7 @@ func main() {
 	nodes := make(map[string]*Node)
 	for _, vid := range vehicleIDs {
 		nodes[vid] = NewNode(vid, cfg, maliciousNodes[vid])
+		nodes[vid].Rm.SetLogger(log.New(logFile, fmt.Sprintf("[节点 %s] ", vid), 0))
 	}
 
 	for _, n := range nodes {
@@ -268,17 +299,62 @@ func main() {
 			vecs = append(vecs, reputation.Vector{
 				Speed:     pts[i].Speed,
 				Location:  pts[i].X,
+				Lateral:   pts[i].Y,
+				Lane:      pts[i].Lane,
 				Direction: dir,
 			})
 		}
 		trajMap[vid] = vecs
 	}
 
-	// 6. 设置邻居关系
+	// 6. 恢复上次运行保存的信誉状态，并设置邻居关系
+	if cfg.SnapshotDir != "" {
+		for _, vid := range vehicleIDs {
+			path := snapshotPath(cfg.SnapshotDir, vid)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if err := nodes[vid].Rm.LoadFile(path); err != nil {
+				logger.Printf("WARN: 恢复节点 %s 的信誉快照失败: %v\n", vid, err)
+				continue
+			}
+			if !reflect.DeepEqual(nodes[vid].Rm.Config(), cfg) {
+				logger.Printf("WARN: 节点 %s 的快照配置与 config.json 不同, 使用快照中的配置\n", vid)
+			}
+			logger.Printf("已恢复节点 %s 的信誉快照: %s\n", vid, path)
+		}
+	}
+
+	// 每个节点的推荐来源：进程内直接引用，或经回环 HTTP 服务查询
+	sources := make(map[string]reputation.RecommendationSource, len(nodes))
+	var servers []*reputation.LoopbackServer
+	for vid, n := range nodes {
+		if cfg.PeerTransport != reputation.TransportHTTP {
+			sources[vid] = n.Rm
+			continue
+		}
+		srv, err := reputation.StartLoopbackServer(n.Rm)
+		if err != nil {
+			fmt.Println("启动推荐服务失败:", err)
+			logger.Println("ERROR: 启动推荐服务失败:", err)
+			return
+		}
+		servers = append(servers, srv)
+		sources[vid] = reputation.NewHTTPSource(srv.URL)
+		logger.Printf("节点 %s 的推荐服务: %s\n", vid, srv.URL)
+	}
+	defer func() {
+		for _, srv := range servers {
+			if err := srv.Close(); err != nil {
+				logger.Println("ERROR:", err)
+			}
+		}
+	}()
+
 	for _, n := range nodes {
-		for pid, peer := range nodes {
+		for pid := range nodes {
 			if pid != n.ID {
-				n.Rm.AddPeer(pid, peer.Rm)
+				n.Rm.AddPeer(pid, sources[pid])
 			}
 		}
 	}
@@ -308,6 +384,8 @@ func main() {
 	}
 	logger.Println()
 
+	detector := reputation.NewCollusionDetector(cfg)
+
 	interChan := make(chan reputation.Interaction, 1000)
 	var wg sync.WaitGroup
 
@@ -332,6 +410,13 @@ func main() {
 		proposer := nodes[vehicleIDs[r%len(vehicleIDs)]]
 		proposer.Propose([]byte(fmt.Sprintf("Round %d positions", r+1)))
 
+		// 各节点记录本轮所有车辆的位置，轨迹相似度按以当前时刻结束的时间窗口计算
+		for _, n := range nodes {
+			for _, vid := range vehicleIDs {
+				n.Rm.RecordTrajectory(vid, roundStartTime, trajMap[vid][r])
+			}
+		}
+
 		// 交互统计
 		roundInteractions := 0
 		honestInteractions := 0
@@ -388,6 +473,23 @@ func main() {
 		wg.Wait()
 		totalInteractions += roundInteractions
 
+		// 共谋与女巫检测，降低被标记推荐者的权重
+		if cfg.CollusionDetection {
+			managers := make(map[string]*reputation.ReputationManager, len(nodes))
+			for vid, n := range nodes {
+				managers[vid] = n.Rm
+			}
+			report := detector.Detect(managers, time.Now())
+			for _, n := range nodes {
+				n.Rm.ApplyCollusionReport(report)
+			}
+			for _, vid := range vehicleIDs {
+				if reason, ok := report.Flagged[vid]; ok {
+					logger.Printf("⚠️ 节点 %s 被标记: %s\n", vid, reason)
+				}
+			}
+		}
+
 		// 计算本轮信誉值
 		logger.Println("========================================")
 		logger.Printf("第 %d 轮信誉计算结果\n", r+1)
@@ -433,7 +535,7 @@ func main() {
 			reputations := make(map[string]float64)
 			for _, target := range vehicleIDs {
 				if target != vid {
-					rep := nodes[vid].Rm.ComputeReputation(vid, target, neighbors, time.Now())
+					rep := nodes[vid].Rm.Model().Score(vid, target, neighbors, time.Now())
 					reputations[target] = rep
 				}
 			}
@@ -518,6 +620,20 @@ func main() {
 
 	close(interChan)
 
+	// 保存信誉状态，下次运行时从此处继续
+	if cfg.SnapshotDir != "" {
+		if err := os.MkdirAll(cfg.SnapshotDir, 0755); err != nil {
+			logger.Println("WARN: 创建快照目录失败:", err)
+		} else {
+			for _, vid := range vehicleIDs {
+				if err := nodes[vid].Rm.SaveFile(snapshotPath(cfg.SnapshotDir, vid)); err != nil {
+					logger.Printf("WARN: 保存节点 %s 的信誉快照失败: %v\n", vid, err)
+				}
+			}
+			logger.Printf("信誉快照已保存到: %s\n", cfg.SnapshotDir)
+		}
+	}
+
 	// 最终总结
 	endTime := time.Now()
 
@@ -529,6 +645,17 @@ func main() {
 	logger.Printf("总节点数: %d (诚实: %d, 恶意: %d)\n", len(vehicleIDs), len(honestNodes), len(malicious))
 	logger.Printf("总交互次数: %d (固定交互模式)\n", totalInteractions)
 	logger.Printf("平均每轮交互次数: %.1f\n", float64(totalInteractions)/float64(rounds))
+
+	var history reputation.HistoryStats
+	for _, vid := range vehicleIDs {
+		st := nodes[vid].Rm.HistoryStats()
+		history.Records += st.Records
+		history.FoldedRecords += st.FoldedRecords
+		history.TrajectoryPoints += st.TrajectoryPoints
+		history.ApproxBytes += st.ApproxBytes
+	}
+	logger.Printf("交互历史: 保留 %d 条, 已压缩 %d 条, 轨迹点 %d 个, 约 %.1f KB\n",
+		history.Records, history.FoldedRecords, history.TrajectoryPoints, float64(history.ApproxBytes)/1024)
 	logger.Println()
 
 	// 最终排名
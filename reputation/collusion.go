@@ -0,0 +1,346 @@
+package reputation
+
+import (
+	"block/config"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CollusionReport 共谋与女巫检测结果
+type CollusionReport struct {
+	Clusters [][]string        `json:"clusters"` // 互相抬高信誉的共谋团体
+	Sybils   [][]string        `json:"sybils"`   // 评价行为几乎相同的身份组
+	Flagged  map[string]string `json:"flagged"`  // 被标记的推荐者 -> 原因
+}
+
+// CollusionDetector 在所有节点的 推荐者→目标 意见图上检测共谋团体与女巫身份
+type CollusionDetector struct {
+	cfg config.Config
+}
+
+// NewCollusionDetector 创建检测器
+func NewCollusionDetector(cfg config.Config) *CollusionDetector {
+	return &CollusionDetector{cfg: cfg}
+}
+
+// 检测参数的默认值
+const (
+	defaultCollusionBoost   = 0.2  // 团体内评价比团体外评价高出的幅度
+	defaultCollusionDensity = 0.8  // 团体内互相抬高的边占所有成员对的比例
+	defaultSybilTolerance   = 0.01 // 两个身份对共同目标的评价最大差异
+	defaultSybilMinCommon   = 3    // 判定女巫身份所需的最少共同目标数
+	defaultSybilTrajectory  = 0.98 // 判定女巫身份所需的最低轨迹相似度
+	defaultCollusionPenalty = 0.1  // 被标记推荐者的权重系数
+)
+
+// Detect 根据 managers（节点 ID -> 信誉管理器）的直接意见构建意见图并检测
+func (d *CollusionDetector) Detect(managers map[string]*ReputationManager, now time.Time) CollusionReport {
+	ids := make([]string, 0, len(managers))
+	for id := range managers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// 意见图：trust[x][y] 为 x 对 y 的直接意见对应的信誉值
+	trust := make(map[string]map[string]float64)
+	for _, x := range ids {
+		rm := managers[x]
+		trust[x] = make(map[string]float64)
+		for _, y := range rm.knownTargets() {
+			if y != x {
+				trust[x][y] = rm.opinionToReputation(rm.localOpinion(y, ContextAny, now))
+			}
+		}
+	}
+
+	report := CollusionReport{Flagged: make(map[string]string)}
+	report.Clusters = d.boostingClusters(ids, trust)
+	for _, cluster := range report.Clusters {
+		for _, id := range cluster {
+			report.Flagged[id] = fmt.Sprintf("共谋团体 %v 互相抬高信誉", cluster)
+		}
+	}
+	report.Sybils = d.sybilGroups(ids, trust, managers)
+	for _, group := range report.Sybils {
+		for _, id := range group {
+			if _, ok := report.Flagged[id]; !ok {
+				report.Flagged[id] = fmt.Sprintf("与 %v 的评价行为几乎相同（疑似女巫身份）", group)
+			}
+		}
+	}
+	return report
+}
+
+// boostingClusters 找出互相抬高的团体：
+// x、y 互评均比其他节点对它们的平均评价高出 CollusionBoost 时连一条边，
+// 连通分量中边的密度不低于 CollusionDensity 时判定为共谋团体
+func (d *CollusionDetector) boostingClusters(ids []string, trust map[string]map[string]float64) [][]string {
+	boost := positiveOr(d.cfg.CollusionBoost, defaultCollusionBoost)
+	density := positiveOr(d.cfg.CollusionDensity, defaultCollusionDensity)
+
+	// outsider 返回除 x、y 外其他节点对 y 的平均评价
+	outsider := func(x, y string) (float64, bool) {
+		var sum float64
+		var n int
+		for _, z := range ids {
+			if z == x || z == y {
+				continue
+			}
+			if t, ok := trust[z][y]; ok {
+				sum += t
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+	boosts := func(x, y string) bool {
+		t, ok := trust[x][y]
+		if !ok {
+			return false
+		}
+		out, ok := outsider(x, y)
+		return ok && t-out >= boost
+	}
+
+	adj := make(map[string][]string)
+	for i, x := range ids {
+		for _, y := range ids[i+1:] {
+			if boosts(x, y) && boosts(y, x) {
+				adj[x] = append(adj[x], y)
+				adj[y] = append(adj[y], x)
+			}
+		}
+	}
+
+	var clusters [][]string
+	visited := make(map[string]bool)
+	for _, start := range ids {
+		if visited[start] || len(adj[start]) == 0 {
+			continue
+		}
+		var component []string
+		edges := 0
+		stack := []string{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			x := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, x)
+			edges += len(adj[x])
+			for _, y := range adj[x] {
+				if !visited[y] {
+					visited[y] = true
+					stack = append(stack, y)
+				}
+			}
+		}
+		n := len(component)
+		if n >= 2 && float64(edges/2)/float64(n*(n-1)/2) >= density {
+			sort.Strings(component)
+			clusters = append(clusters, component)
+		}
+	}
+	return clusters
+}
+
+// sybilGroups 找出对共同目标的评价几乎完全相同的身份组。
+// 两个身份都有轨迹时还要求轨迹相似度不低于 SybilTrajectory，
+// 避免把行为同样诚实、但位于不同位置的车辆误判为同一实体。
+// 评价一致本身不足以判定：只有成员都有几乎相同的轨迹（独立的身份信号），
+// 或组内评价偏离组外多数评价 CollusionBoost 以上时，才判定为女巫身份组
+func (d *CollusionDetector) sybilGroups(ids []string, trust map[string]map[string]float64, managers map[string]*ReputationManager) [][]string {
+	tolerance := positiveOr(d.cfg.SybilTolerance, defaultSybilTolerance)
+	minSim := positiveOr(d.cfg.SybilTrajectory, defaultSybilTrajectory)
+	minCommon := d.cfg.SybilMinCommon
+	if minCommon <= 0 {
+		minCommon = defaultSybilMinCommon
+	}
+
+	trajs := make(map[string][]Vector)
+	for _, id := range ids {
+		trajs[id] = managers[id].ownTrajectory(id)
+	}
+
+	similar := func(x, y string) bool {
+		if len(trajs[x]) > 0 && len(trajs[y]) > 0 &&
+			managers[x].computeTrajectorySimilarity(trajs[x], trajs[y]) < minSim {
+			return false
+		}
+
+		common := 0
+		for target, tx := range trust[x] {
+			if target == y {
+				continue
+			}
+			ty, ok := trust[y][target]
+			if !ok {
+				continue
+			}
+			if math.Abs(tx-ty) > tolerance {
+				return false
+			}
+			common++
+		}
+		return common >= minCommon
+	}
+
+	// 并查集合并相似身份
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] == "" || parent[x] == x {
+			return x
+		}
+		parent[x] = find(parent[x])
+		return parent[x]
+	}
+	for i, x := range ids {
+		for _, y := range ids[i+1:] {
+			if similar(x, y) && similar(y, x) {
+				parent[find(y)] = find(x)
+			}
+		}
+	}
+
+	groups := make(map[string][]string)
+	for _, id := range ids {
+		root := find(id)
+		groups[root] = append(groups[root], id)
+	}
+	var result [][]string
+	for _, id := range ids {
+		g := groups[id]
+		if len(g) < 2 {
+			continue
+		}
+		sameTrajectory := true
+		for _, x := range g {
+			sameTrajectory = sameTrajectory && len(trajs[x]) > 0
+		}
+		if sameTrajectory || d.deviatesFromMajority(g, ids, trust) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// deviatesFromMajority 判断组内对各目标的平均评价与组外节点的平均评价之差，
+// 在所有组外也评价过的目标上平均是否达到 CollusionBoost；没有可比较的目标时返回 false
+func (d *CollusionDetector) deviatesFromMajority(group, ids []string, trust map[string]map[string]float64) bool {
+	boost := positiveOr(d.cfg.CollusionBoost, defaultCollusionBoost)
+	member := make(map[string]bool, len(group))
+	for _, x := range group {
+		member[x] = true
+	}
+	mean := func(raters []string, target string, inGroup bool) (float64, bool) {
+		var sum float64
+		var n int
+		for _, z := range raters {
+			if member[z] != inGroup || z == target {
+				continue
+			}
+			if t, ok := trust[z][target]; ok {
+				sum += t
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+
+	// 按字典序遍历目标，保证累加顺序确定
+	seen := make(map[string]bool)
+	var targets []string
+	for _, x := range group {
+		for target := range trust[x] {
+			if !seen[target] && !member[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	sort.Strings(targets)
+
+	var dev float64
+	var compared int
+	for _, target := range targets {
+		in, ok := mean(group, target, true)
+		if !ok {
+			continue
+		}
+		out, ok := mean(ids, target, false)
+		if !ok {
+			continue
+		}
+		dev += math.Abs(in - out)
+		compared++
+	}
+	return compared > 0 && dev/float64(compared) >= boost
+}
+
+// ApplyCollusionReport 降低被标记推荐者在推荐意见中的权重
+func (rm *ReputationManager) ApplyCollusionReport(report CollusionReport) {
+	penalty := positiveOr(rm.cfg.CollusionPenalty, defaultCollusionPenalty)
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.flagged = make(map[string]flaggedRecommender, len(report.Flagged))
+	for id, reason := range report.Flagged {
+		rm.flagged[id] = flaggedRecommender{penalty: penalty, reason: reason}
+	}
+}
+
+// flaggedRecommender 被标记推荐者的权重系数与原因
+type flaggedRecommender struct {
+	penalty float64
+	reason  string
+}
+
+// recommenderFlag 返回推荐者的权重系数，未被标记时为 1
+func (rm *ReputationManager) recommenderFlag(id string) (float64, string) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	if f, ok := rm.flagged[id]; ok {
+		return f.penalty, f.reason
+	}
+	return 1, ""
+}
+
+// knownTargets 返回本节点有直接证据的目标（按字典序）
+func (rm *ReputationManager) knownTargets() []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	targets := make([]string, 0, len(rm.store.byTo))
+	for target := range rm.store.byTo {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// ownTrajectory 返回本节点记录中 id 作为请求者时的轨迹
+func (rm *ReputationManager) ownTrajectory(id string) []Vector {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	for _, pe := range rm.store.byFrom[id] {
+		if len(pe.trajUser) > 0 {
+			return pe.trajUser
+		}
+	}
+	return nil
+}
+
+// positiveOr 返回 v，v 不为正时返回默认值 def
+func positiveOr(v, def float64) float64 {
+	if v > 0 {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,101 @@
+package reputation
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rateTargets 让 managers[from] 与各目标交互一次
+func rateTargets(managers map[string]*ReputationManager, from string, now time.Time, pos, neg int, targets ...string) {
+	for _, to := range targets {
+		managers[from].AddInteraction(Interaction{From: from, To: to, PosEvents: pos, NegEvents: neg, Timestamp: now, CommQuality: 0.9})
+	}
+}
+
+func newManagers(ids ...string) map[string]*ReputationManager {
+	managers := make(map[string]*ReputationManager, len(ids))
+	for _, id := range ids {
+		managers[id] = NewReputationManager(testConfig())
+	}
+	return managers
+}
+
+// TestCollusionBoostingClique 互相抬高、而其他节点评价很低的团体被检测为共谋团体，诚实节点不被标记
+func TestCollusionBoostingClique(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	clique := []string{"c0", "c1", "c2"}
+	honest := []string{"h0", "h1", "h2"}
+	managers := newManagers(append(append([]string{}, clique...), honest...)...)
+
+	for _, c := range clique {
+		for _, other := range clique {
+			if other != c {
+				rateTargets(managers, c, now, 30, 0, other)
+			}
+		}
+	}
+	for k, h := range honest {
+		rateTargets(managers, h, now, 0, 60+20*k, clique...)
+		for _, other := range honest {
+			if other != h {
+				rateTargets(managers, h, now, 10+5*k, 2, other)
+			}
+		}
+	}
+
+	report := NewCollusionDetector(testConfig()).Detect(managers, now)
+	if !reflect.DeepEqual(report.Clusters, [][]string{clique}) {
+		t.Errorf("clusters = %v, want [%v]", report.Clusters, clique)
+	}
+	for _, h := range honest {
+		if reason, ok := report.Flagged[h]; ok {
+			t.Errorf("honest node %s flagged: %s", h, reason)
+		}
+	}
+}
+
+// TestSybilDetection 评价完全相同且偏离多数评价的身份组被判定为女巫，评价一致的诚实节点不被判定
+func TestSybilDetection(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	targets := []string{"t0", "t1", "t2", "t3"}
+
+	t.Run("honest agreement", func(t *testing.T) {
+		var ids []string
+		for k := 0; k < 4; k++ {
+			ids = append(ids, fmt.Sprintf("h%d", k))
+		}
+		managers := newManagers(ids...)
+		for _, h := range ids {
+			rateTargets(managers, h, now, 10, 1, targets...)
+		}
+
+		report := NewCollusionDetector(testConfig()).Detect(managers, now)
+		if len(report.Sybils) != 0 || len(report.Flagged) != 0 {
+			t.Errorf("honest raters flagged: sybils=%v flagged=%v", report.Sybils, report.Flagged)
+		}
+	})
+
+	t.Run("boosting sybils", func(t *testing.T) {
+		sybils := []string{"s0", "s1", "s2"}
+		honest := []string{"h0", "h1", "h2"}
+		managers := newManagers(append(append([]string{}, sybils...), honest...)...)
+		for _, s := range sybils {
+			rateTargets(managers, s, now, 30, 0, targets...)
+		}
+		for k, h := range honest {
+			rateTargets(managers, h, now, 0, 60+20*k, targets...)
+		}
+
+		report := NewCollusionDetector(testConfig()).Detect(managers, now)
+		if !reflect.DeepEqual(report.Sybils, [][]string{sybils}) {
+			t.Errorf("sybils = %v, want [%v]", report.Sybils, sybils)
+		}
+		for _, h := range honest {
+			if reason, ok := report.Flagged[h]; ok {
+				t.Errorf("honest node %s flagged: %s", h, reason)
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package reputation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"block/config"
+)
+
+// testConfig 测试使用的论文默认参数
+func testConfig() config.Config {
+	return config.Config{
+		Gamma: 0.5, Rho1: 0.5, Rho2: 0.5,
+		Zeta: 0.7, Sigma: 0.3, Theta: 1, Tau: 1,
+		Psi1: 0.4, Psi2: 0.3, Psi3: 0.3,
+		TRecent:        1000,
+		FusionOperator: FusionCumulative,
+	}
+}
+
+// TestConcurrentManagers 多个互为邻居的管理器同时写入交互、增加邻居并查询信誉，
+// 需以 go test -race 运行
+func TestConcurrentManagers(t *testing.T) {
+	const (
+		nodes   = 5
+		workers = 4
+		rounds  = 200
+	)
+
+	ids := make([]string, nodes)
+	managers := make([]*ReputationManager, nodes)
+	for i := range managers {
+		ids[i] = fmt.Sprintf("v%d", i)
+		managers[i] = NewReputationManager(testConfig())
+	}
+
+	base := time.Unix(1_000_000, 0)
+	traj := []Vector{{Speed: 10, Location: 0.2, Direction: 0.1}}
+
+	var wg sync.WaitGroup
+	for i, rm := range managers {
+		i, rm := i, rm
+		for w := 0; w < workers; w++ {
+			w := w
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for r := 0; r < rounds; r++ {
+					now := base.Add(time.Duration(r) * time.Second)
+					from, to := ids[i], ids[(i+1+r%(nodes-1))%nodes]
+					switch (w + r) % 5 {
+					case 0:
+						rm.AddInteraction(Interaction{
+							From: from, To: to, PosEvents: 1 + r%2, NegEvents: r % 3,
+							Timestamp: now, CommQuality: 0.8, TrajUser: traj, TrajProvider: traj,
+						})
+					case 1:
+						// 重复添加邻居，与推荐查询并发
+						peer := (i + 1 + r) % nodes
+						if peer != i {
+							rm.AddPeer(ids[peer], managers[peer])
+						}
+					case 2:
+						rep := rm.ComputeReputation(from, to, ids, now)
+						if math.IsNaN(rep) || rep < 0 || rep > 1 {
+							t.Errorf("ComputeReputation(%s, %s) = %v", from, to, rep)
+						}
+					case 3:
+						rm.SelectOptimalProvider(from, ContextAny, ids, ids, now)
+					case 4:
+						b := rm.ExplainReputation(from, to, ids, now)
+						if b.Target != to {
+							t.Errorf("ExplainReputation target = %s, want %s", b.Target, to)
+						}
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for i, rm := range managers {
+		if got := len(rm.GetInteractions()); got != workers*rounds/5 {
+			t.Errorf("manager %s: %d interactions, want %d", ids[i], got, workers*rounds/5)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package reputation
+
+import (
+	"math"
+	"testing"
+)
+
+func headings(dirs ...float64) []Vector {
+	traj := make([]Vector, len(dirs))
+	for k, d := range dirs {
+		traj[k] = Vector{Speed: 10, Direction: d}
+	}
+	return traj
+}
+
+func TestAngleBetweenWrapAround(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{0, 0, 0},
+		{math.Pi - 0.05, -math.Pi + 0.05, 0.1},
+		{-math.Pi + 0.05, math.Pi - 0.05, 0.1},
+		{0.1, 2*math.Pi + 0.1, 0},
+		{0, math.Pi, math.Pi},
+	}
+	for _, tt := range tests {
+		if got := angleBetween(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("angleBetween(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestMeanDirectionWrapAround 航向在 ±π 两侧来回跳变的轨迹，圆周平均方向仍为 π，
+// 与朝向 π 的轨迹差异约为 0（算术平均会得到 0，差异约为 π）；方向分散的相同轨迹差异为 0
+func TestMeanDirectionWrapAround(t *testing.T) {
+	rm := NewReputationManager(testConfig())
+
+	tests := []struct {
+		name         string
+		traj1, traj2 []Vector
+		want         float64
+	}{
+		{"wrapped vs pi", headings(math.Pi-0.1, -math.Pi+0.1, math.Pi-0.1, -math.Pi+0.1), headings(math.Pi, math.Pi), 0},
+		{"wrapped vs wrapped", headings(math.Pi-0.05, -math.Pi+0.05), headings(-math.Pi+0.05, math.Pi-0.05), 0},
+		{"opposite", headings(0, 0), headings(math.Pi, -math.Pi), directionPenalty(math.Pi)},
+		// 方向分散的相同轨迹：平均方向没有意义，但两者完全一致
+		{"identical dispersed", headings(0, math.Pi), headings(0, math.Pi), 0},
+		{"identical spread", headings(0, math.Pi/2, math.Pi, -math.Pi/2), headings(0, math.Pi/2, math.Pi, -math.Pi/2), 0},
+		// 一条方向一致、一条来回掉头：离散程度的差异计入
+		{"steady vs dispersed", headings(0, 0), headings(0, math.Pi), 1},
+	}
+	for _, tt := range tests {
+		if got := rm.computeDirectionDifference(tt.traj1, tt.traj2); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: difference = %v, want ≈ %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestAlignedDirectionWrapAround aligned 模式逐点比较，跨越 ±π 的点对按实际夹角计算
+func TestAlignedDirectionWrapAround(t *testing.T) {
+	cfg := testConfig()
+	cfg.DirectionMode = DirectionAligned
+	rm := NewReputationManager(cfg)
+
+	// 每对点的夹角都是 0.1
+	traj1 := headings(math.Pi-0.05, -math.Pi+0.05, math.Pi-0.05)
+	traj2 := headings(-math.Pi+0.05, math.Pi-0.05, -math.Pi+0.05)
+	if got, want := rm.computeDirectionDifference(traj1, traj2), math.Sin(0.1); math.Abs(got-want) > 1e-9 {
+		t.Errorf("wrapped pairs: difference = %v, want %v", got, want)
+	}
+
+	// 不同长度的轨迹按相对位置对齐：前半段同向，后半段反向
+	traj1 = headings(math.Pi, math.Pi, 0, 0)
+	traj2 = headings(-math.Pi, -math.Pi)
+	if got, want := rm.computeDirectionDifference(traj1, traj2), directionPenalty(math.Pi)/2; math.Abs(got-want) > 1e-9 {
+		t.Errorf("half reversed: difference = %v, want %v", got, want)
+	}
+}
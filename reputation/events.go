@@ -0,0 +1,70 @@
+package reputation
+
+// 事件类型，严重程度权重通过 config.Config.EventSeverity 配置
+const (
+	EventPositive       = "positive"        // 正常交付的数据，PosEvents 的默认类型
+	EventNegative       = "negative"        // 一般负面事件，NegEvents 的默认类型
+	EventForgedData     = "forged_data"     // 伪造数据（如虚假事故报告）
+	EventDelayedData    = "delayed_data"    // 数据延迟到达
+	EventDroppedRequest = "dropped_request" // 请求被丢弃
+)
+
+// defaultEventSeverity 未配置时各事件类型的严重程度
+var defaultEventSeverity = map[string]float64{
+	EventPositive:       1,
+	EventNegative:       1,
+	EventForgedData:     5,
+	EventDelayedData:    0.3,
+	EventDroppedRequest: 1,
+}
+
+// Event 一次交互中某一类型事件的发生次数
+type Event struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// IsPositiveEvent 只有 EventPositive 计入正面证据 α，其他类型（包括未知类型）均计入负面证据 β
+func IsPositiveEvent(eventType string) bool {
+	return eventType == EventPositive
+}
+
+// eventSeverity 返回事件类型的严重程度：优先使用配置，其次使用默认值，未知类型按 1 计
+func eventSeverity(severity map[string]float64, eventType string) float64 {
+	if w, ok := severity[eventType]; ok && w >= 0 {
+		return w
+	}
+	if w, ok := defaultEventSeverity[eventType]; ok {
+		return w
+	}
+	return 1
+}
+
+// eventEvidence 返回一次交互按严重程度加权后的正、负证据，
+// PosEvents/NegEvents 分别按 EventPositive/EventNegative 计。
+// 带评价向量的交互与 ratingEvidence 一致只使用评价：按各等级的效用 v_k 投影，
+// 正面证据 Σr_k×v_k、负面证据 Σr_k×(1-v_k)，与 Dirichlet 模型的二项投影相同
+func eventEvidence(severity map[string]float64, utility []float64, inter Interaction) (pos, neg float64) {
+	if len(inter.Ratings) > 0 {
+		for k, r := range inter.Ratings {
+			if k >= len(utility) {
+				break
+			}
+			pos += r * utility[k]
+			neg += r * (1 - utility[k])
+		}
+		return pos, neg
+	}
+
+	pos = float64(inter.PosEvents) * eventSeverity(severity, EventPositive)
+	neg = float64(inter.NegEvents) * eventSeverity(severity, EventNegative)
+	for _, e := range inter.Events {
+		w := float64(e.Count) * eventSeverity(severity, e.Type)
+		if IsPositiveEvent(e.Type) {
+			pos += w
+		} else {
+			neg += w
+		}
+	}
+	return pos, neg
+}
@@ -0,0 +1,200 @@
+package reputation
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// EvidenceBreakdown 本地意见所用的证据，α/β 按近期 (Δt <= TRecent) 与过去拆分
+type EvidenceBreakdown struct {
+	AlphaRecent  float64 `json:"alpha_recent"`
+	AlphaPast    float64 `json:"alpha_past"`
+	BetaRecent   float64 `json:"beta_recent"`
+	BetaPast     float64 `json:"beta_past"`
+	Alpha        float64 `json:"alpha"`
+	Beta         float64 `json:"beta"`
+	Interactions int     `json:"interactions"`
+	CommQuality  float64 `json:"comm_quality"` // 平均通信质量 s_{i→j}
+}
+
+// NeighborContribution 单个邻居的推荐意见及其权重 δ = ρ₁×IF + ρ₂×SIM
+type NeighborContribution struct {
+	ID string `json:"id"`
+	Recommendation
+	Flag     string  `json:"flag,omitempty"`     // 被标记为共谋/女巫时的原因
+	Rejected string  `json:"rejected,omitempty"` // 被过滤时的原因
+	Share    float64 `json:"share"`              // 在公式11中的权重占比 δ/Σδ
+}
+
+// WeightDistribution 公式11中各推荐权重 δ 的分布，MaxShare 接近 1 说明单个邻居主导了推荐意见
+type WeightDistribution struct {
+	Count    int     `json:"count"` // 权重为正的推荐数量
+	Sum      float64 `json:"sum"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Mean     float64 `json:"mean"`
+	MaxShare float64 `json:"max_share"` // 最大权重在总权重中的占比
+}
+
+// ReputationBreakdown 一次 (requester, target) 信誉查询的完整计算过程
+type ReputationBreakdown struct {
+	Requester      string                 `json:"requester"`
+	Target         string                 `json:"target"`
+	Context        string                 `json:"context,omitempty"`
+	Time           time.Time              `json:"time"`
+	Evidence       EvidenceBreakdown      `json:"evidence"`
+	Local          Opinion                `json:"local"`
+	Neighbors      []NeighborContribution `json:"neighbors"`
+	Weights        WeightDistribution     `json:"weights"`
+	Recommended    Opinion                `json:"recommended"`
+	FusionOperator string                 `json:"fusion_operator"`
+	Fused          Opinion                `json:"fused"`
+	Reputation     float64                `json:"reputation"` // T = b + a×u
+	Interval       ReputationInterval     `json:"interval"`
+	Newcomer       NewcomerStatus         `json:"newcomer"`
+	Forgiveness    *ForgivenessStatus     `json:"forgiveness,omitempty"`
+}
+
+// ExplainReputation 返回 ComputeReputation 的结构化计算过程，可直接序列化为 JSON
+func (rm *ReputationManager) ExplainReputation(myID, target string, neighbors []string, now time.Time) ReputationBreakdown {
+	return rm.ExplainContextReputation(myID, target, ContextAny, neighbors, now)
+}
+
+// ExplainContextReputation 返回 ComputeContextReputation 的结构化计算过程
+func (rm *ReputationManager) ExplainContextReputation(myID, target, context string, neighbors []string, now time.Time) ReputationBreakdown {
+	rm.mu.RLock()
+	evidence := rm.evidenceBreakdown(target, context, now)
+	local := rm.computeDirectOpinion(target, context, now)
+	rm.mu.RUnlock()
+
+	contributions := rm.collectRecommendations(myID, target, context, neighbors, now)
+	rm.filterRecommendations(target, local, contributions)
+	weights := weightDistribution(contributions)
+	recommended := aggregateRecommendations(contributions, local.BaseRate)
+	fused := rm.combineOpinions(local, recommended)
+	reputation, newcomer := rm.finalReputation(target, fused, now)
+
+	operator := rm.cfg.FusionOperator
+	if operator == "" {
+		operator = FusionLocal
+	}
+
+	return ReputationBreakdown{
+		Requester:      myID,
+		Target:         target,
+		Context:        context,
+		Time:           now,
+		Evidence:       evidence,
+		Local:          local,
+		Neighbors:      contributions,
+		Weights:        weights,
+		Recommended:    recommended,
+		FusionOperator: operator,
+		Fused:          fused,
+		Reputation:     reputation,
+		Interval:       rm.reputationInterval(target, context, reputation, now),
+		Newcomer:       newcomer,
+		Forgiveness:    rm.forgivenessStatus(target, now),
+	}
+}
+
+// weightDistribution 统计推荐权重的分布，并填写各推荐的权重占比
+func weightDistribution(contributions []NeighborContribution) WeightDistribution {
+	var wd WeightDistribution
+	for _, c := range contributions {
+		if c.Weight <= 0 {
+			continue
+		}
+		if wd.Count == 0 || c.Weight < wd.Min {
+			wd.Min = c.Weight
+		}
+		wd.Max = math.Max(wd.Max, c.Weight)
+		wd.Sum += c.Weight
+		wd.Count++
+	}
+	if wd.Count == 0 {
+		return wd
+	}
+
+	wd.Mean = wd.Sum / float64(wd.Count)
+	wd.MaxShare = wd.Max / wd.Sum
+	for k := range contributions {
+		contributions[k].Share = math.Max(contributions[k].Weight, 0) / wd.Sum
+	}
+	return wd
+}
+
+// evidenceBreakdown 按近期/过去拆分对 target 的证据，其他上下文的证据按 contextWeight 折算，
+// 调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) evidenceBreakdown(target, context string, now time.Time) EvidenceBreakdown {
+	var eb EvidenceBreakdown
+	for _, pe := range rm.store.byTo[target] {
+		w := rm.contextWeight(pe.key.Context, context)
+		if w == 0 {
+			continue
+		}
+		alpha, beta := rm.pairAlphaBeta(pe, now)
+		eb.Alpha += w * alpha
+		eb.Beta += w * beta
+
+		var alphaRecent, alphaPast, betaRecent, betaPast float64
+		// 已压缩的记录：bucket 模式下一定早于 TRecent，exponential 模式下按其中最新的时间划分
+		if pe.folded.Count > 0 {
+			if rm.cfg.DecayMode == DecayExponential {
+				pos, neg := pe.foldedDecayed(now, rm.store.lambdaPos, rm.store.lambdaNeg)
+				if now.Sub(pe.folded.Until).Seconds() <= rm.cfg.TRecent {
+					alphaRecent += rm.cfg.Theta * pos
+					betaRecent += rm.cfg.Tau * neg
+				} else {
+					alphaPast += rm.cfg.Theta * pos
+					betaPast += rm.cfg.Tau * neg
+				}
+			} else {
+				alphaPast += rm.cfg.Sigma * rm.cfg.Theta * pe.folded.Pos
+				betaPast += rm.cfg.Sigma * rm.cfg.Tau * pe.folded.Neg
+			}
+		}
+		for k, ts := range pe.times {
+			deltaTime := now.Sub(ts).Seconds()
+			wPos, wNeg := rm.evidenceWeights(deltaTime)
+			pos := wPos * (pe.cumPos[k+1] - pe.cumPos[k])
+			neg := wNeg * (pe.cumNeg[k+1] - pe.cumNeg[k])
+			if deltaTime <= rm.cfg.TRecent {
+				alphaRecent += pos
+				betaRecent += neg
+			} else {
+				alphaPast += pos
+				betaPast += neg
+			}
+		}
+		eb.AlphaRecent += w * alphaRecent
+		eb.AlphaPast += w * alphaPast
+		eb.BetaRecent += w * betaRecent
+		eb.BetaPast += w * betaPast
+		eb.Interactions += pe.count()
+	}
+
+	eb.CommQuality = rm.commQuality(target, context)
+	return eb
+}
+
+// evidenceWeights 返回距今 deltaTime 秒的单条交互中正、负事件的权重，与 pairAlphaBeta 一致
+func (rm *ReputationManager) evidenceWeights(deltaTime float64) (wPos, wNeg float64) {
+	if rm.cfg.DecayMode == DecayExponential {
+		deltaTime = math.Max(deltaTime, 0)
+		wPos = rm.cfg.Theta * math.Exp(-rm.store.lambdaPos*deltaTime)
+		wNeg = rm.cfg.Tau * math.Exp(-rm.store.lambdaNeg*deltaTime)
+		return wPos, wNeg
+	}
+
+	if deltaTime <= rm.cfg.TRecent {
+		return rm.cfg.Zeta * rm.cfg.Theta, rm.cfg.Zeta * rm.cfg.Tau
+	}
+	return rm.cfg.Sigma * rm.cfg.Theta, rm.cfg.Sigma * rm.cfg.Tau
+}
+
+// JSON 将计算过程序列化为缩进的 JSON
+func (b ReputationBreakdown) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
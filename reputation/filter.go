@@ -0,0 +1,114 @@
+package reputation
+
+import (
+	"fmt"
+	"math"
+)
+
+// 不诚实推荐过滤规则，通过 config.Config.RecFilter 选择
+const (
+	FilterNone      = "none"      // 不过滤（默认）
+	FilterQuantile  = "quantile"  // 多数意见期望值超出推荐自身 Beta 分布分位区间时剔除（迭代）
+	FilterDeviation = "deviation" // 基于与本地意见偏差的过滤，偏差越大权重越低
+)
+
+// 过滤参数默认值
+const (
+	defaultRecQuantile  = 0.01 // quantile 规则的分位数 q
+	defaultRecDeviation = 0.3  // deviation 规则的最大允许偏差
+)
+
+// opinionEvidence 将意见换算为 Beta 证据 (r, s)，与 Opinion.Evidence 相同但对教条意见截断，保证结果有限
+func opinionEvidence(op Opinion) (r, s float64) {
+	u := math.Max(op.Uncertainty, 1e-6)
+	return priorWeight * op.Belief / u, priorWeight * op.Disbelief / u
+}
+
+// filterRecommendations 剔除或降权偏离本地意见或多数意见过远的推荐（抵御恶意诋毁与虚假夸赞），
+// 被剔除的推荐权重置 0，并在 Rejected 中记录原因
+func (rm *ReputationManager) filterRecommendations(target string, local Opinion, contributions []NeighborContribution) {
+	switch rm.cfg.RecFilter {
+	case FilterQuantile:
+		rm.quantileFilter(local, contributions)
+	case FilterDeviation:
+		rm.deviationFilter(local, contributions)
+	default:
+		return
+	}
+
+	for _, c := range contributions {
+		if c.Rejected != "" {
+			rm.logf("拒绝推荐: 目标=%s 推荐者=%s 原因=%s", target, c.ID, c.Rejected)
+		}
+	}
+}
+
+// quantileFilter 迭代过滤（Whitby 等）：以本地证据与尚未剔除的推荐证据之和作为多数意见，
+// 其期望值 E = (R+1)/(R+S+2) 落在某条推荐自身 Beta(r+1, s+1) 的 [q, 1-q] 分位区间之外时该推荐与多数不符。
+// 每轮剔除多数期望值偏离其区间最远的一条推荐并重新计算多数意见，直到多数期望值落在所有推荐的区间内
+func (rm *ReputationManager) quantileFilter(local Opinion, contributions []NeighborContribution) {
+	q := rm.cfg.RecQuantile
+	if q <= 0 || q >= 0.5 {
+		q = defaultRecQuantile
+	}
+
+	for {
+		R, S := opinionEvidence(local)
+		for _, c := range contributions {
+			if c.Rejected == "" && c.Weight > 0 {
+				r, s := opinionEvidence(c.Opinion)
+				R += r
+				S += s
+			}
+		}
+		majority := (R + 1) / (R + S + 2)
+
+		worst, worstDist := -1, 0.0
+		var worstLower, worstUpper float64
+		for k, c := range contributions {
+			if c.Rejected != "" || c.Weight <= 0 {
+				continue
+			}
+			r, s := opinionEvidence(c.Opinion)
+			if r+s == 0 {
+				continue // 没有证据的推荐不参与判断
+			}
+			lower := betaQuantile(q, r+1, s+1)
+			upper := betaQuantile(1-q, r+1, s+1)
+			dist := math.Max(lower-majority, majority-upper)
+			if dist > worstDist {
+				worst, worstDist, worstLower, worstUpper = k, dist, lower, upper
+			}
+		}
+		if worst < 0 {
+			return
+		}
+		contributions[worst].Rejected = fmt.Sprintf("多数意见的期望值 %.4f 超出推荐的 [%.2f, %.2f] 分位区间 [%.4f, %.4f]", majority, q, 1-q, worstLower, worstUpper)
+		contributions[worst].Weight = 0
+	}
+}
+
+// deviationFilter 按推荐与本地意见期望值的偏差过滤：偏差超过阈值时剔除，否则按偏差线性降权。
+// 本地意见越不确定，允许的偏差越大，本地完全没有证据时不过滤。
+func (rm *ReputationManager) deviationFilter(local Opinion, contributions []NeighborContribution) {
+	threshold := rm.cfg.RecDeviation
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultRecDeviation
+	}
+	threshold += local.Uncertainty * (1 - threshold)
+	localE := rm.opinionToReputation(local)
+
+	for k := range contributions {
+		c := &contributions[k]
+		if c.Weight <= 0 {
+			continue
+		}
+		dev := math.Abs(rm.opinionToReputation(c.Opinion) - localE)
+		if dev > threshold {
+			c.Rejected = fmt.Sprintf("与本地意见的偏差 %.4f 超过阈值 %.4f", dev, threshold)
+			c.Weight = 0
+			continue
+		}
+		c.Weight *= 1 - dev/threshold
+	}
+}
@@ -0,0 +1,38 @@
+package reputation
+
+import "testing"
+
+// TestQuantileFilter 诚实推荐者一致认为目标良好时全部保留，只剔除唯一的诋毁者
+func TestQuantileFilter(t *testing.T) {
+	cfg := testConfig()
+	cfg.RecFilter = FilterQuantile
+	cfg.RecQuantile = 0.01
+	rm := NewReputationManager(cfg)
+
+	// 诚实推荐者的证据量差别很大，但正面证据都不低于 97%
+	honest := [][2]float64{{1, 0}, {2, 0}, {5, 0}, {20, 0}, {30, 1}, {40, 0}, {60, 1}, {100, 2}}
+	var contributions []NeighborContribution
+	for k, e := range honest {
+		contributions = append(contributions, NeighborContribution{
+			ID:             string(rune('a' + k)),
+			Recommendation: Recommendation{Opinion: OpinionFromEvidence(e[0], e[1], 0.5), Weight: 1},
+		})
+	}
+	contributions = append(contributions, NeighborContribution{
+		ID:             "badmouther",
+		Recommendation: Recommendation{Opinion: OpinionFromEvidence(0, 50, 0.5), Weight: 1},
+	})
+
+	rm.filterRecommendations("target", OpinionFromEvidence(10, 0, 0.5), contributions)
+	for _, c := range contributions {
+		if c.ID == "badmouther" {
+			if c.Rejected == "" || c.Weight != 0 {
+				t.Errorf("bad-mouther kept with weight %v", c.Weight)
+			}
+			continue
+		}
+		if c.Rejected != "" || c.Weight != 1 {
+			t.Errorf("honest recommender %s rejected: %s", c.ID, c.Rejected)
+		}
+	}
+}
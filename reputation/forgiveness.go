@@ -0,0 +1,104 @@
+package reputation
+
+import (
+	"math"
+	"time"
+)
+
+// 宽恕模型的默认参数
+const (
+	defaultSevereOffence = 5.0 // 单次交互的负面证据达到该值即为严重违规（一次伪造数据）
+	defaultMaxRecovery   = 0.8 // 每次严重违规后可恢复到的最高信誉比例
+)
+
+// offenceRecord 某个目标的不良行为记录
+type offenceRecord struct {
+	Offences int       // 负面证据多于正面证据的交互次数
+	Severe   int       // 严重违规次数
+	Floor    float64   // 最近一次不良行为后的本地信誉
+	At       time.Time // 最近一次不良行为的时间
+}
+
+// ForgivenessStatus 目标在宽恕模型下的状态
+type ForgivenessStatus struct {
+	Offences       int       `json:"offences"`
+	SevereOffences int       `json:"severe_offences"`
+	LastOffence    time.Time `json:"last_offence"`
+	Floor          float64   `json:"floor"`   // 最近一次不良行为后的本地信誉
+	Ceiling        float64   `json:"ceiling"` // 严重违规后可恢复到的最高信誉
+	Limit          float64   `json:"limit"`   // 当前允许的最高信誉
+}
+
+// forgivenessEnabled 是否启用宽恕模型
+func (rm *ReputationManager) forgivenessEnabled() bool {
+	return rm.cfg.RedemptionRate > 0
+}
+
+// noteOffence 记录一次交互中的不良行为：负面证据多于正面证据时，
+// 以此刻的本地信誉作为恢复的起点。调用方需持有 rm.mu 的写锁，且交互已加入证据索引
+func (rm *ReputationManager) noteOffence(inter Interaction) {
+	if !rm.forgivenessEnabled() {
+		return
+	}
+	pos, neg := rm.interactionEvidence(inter)
+	if neg <= pos {
+		return
+	}
+
+	rec, ok := rm.offences[inter.To]
+	if !ok {
+		rec = &offenceRecord{}
+		rm.offences[inter.To] = rec
+	}
+	rec.Offences++
+	if neg >= positiveOr(rm.cfg.SevereOffence, defaultSevereOffence) {
+		rec.Severe++
+	}
+	if !inter.Timestamp.Before(rec.At) {
+		rec.Floor = rm.opinionToReputation(rm.computeDirectOpinion(inter.To, ContextAny, inter.Timestamp))
+		rec.At = inter.Timestamp
+	}
+}
+
+// offenceRecords 返回不良行为记录的副本，调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) offenceRecords() map[string]offenceRecord {
+	if len(rm.offences) == 0 {
+		return nil
+	}
+	out := make(map[string]offenceRecord, len(rm.offences))
+	for id, rec := range rm.offences {
+		out[id] = *rec
+	}
+	return out
+}
+
+// forgivenessStatus 返回目标的宽恕状态，没有不良行为记录时返回 nil。
+// 信誉损失立即生效，恢复则受两条限制（慢增快减）：
+// 自最近一次不良行为起每秒最多恢复 RedemptionRate；
+// 每次严重违规后可恢复的最高信誉乘以 MaxRecovery。
+func (rm *ReputationManager) forgivenessStatus(target string, now time.Time) *ForgivenessStatus {
+	if !rm.forgivenessEnabled() {
+		return nil
+	}
+	rm.mu.RLock()
+	rec, ok := rm.offences[target]
+	var copied offenceRecord
+	if ok {
+		copied = *rec
+	}
+	rm.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	st := &ForgivenessStatus{
+		Offences:       copied.Offences,
+		SevereOffences: copied.Severe,
+		LastOffence:    copied.At,
+		Floor:          copied.Floor,
+		Ceiling:        math.Pow(positiveOr(rm.cfg.MaxRecovery, defaultMaxRecovery), float64(copied.Severe)),
+	}
+	elapsed := math.Max(now.Sub(copied.At).Seconds(), 0)
+	st.Limit = math.Min(st.Ceiling, copied.Floor+rm.cfg.RedemptionRate*elapsed)
+	return st
+}
@@ -0,0 +1,61 @@
+package reputation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestOnOffAttacker 交替表现良好与发起严重违规的节点：信誉下降立即生效，
+// 恢复速度不超过 RedemptionRate，恢复上限不超过 MaxRecovery^严重违规次数
+func TestOnOffAttacker(t *testing.T) {
+	const eps = 1e-9
+	cfg := testConfig()
+	cfg.RedemptionRate = 0.0005
+	cfg.SevereOffence = 5
+	cfg.MaxRecovery = 0.8
+	rm := NewReputationManager(cfg)
+
+	now := time.Unix(1_000_000, 0)
+	var severe int
+	var floor float64       // 最近一次违规后的信誉
+	var offenceAt time.Time // 最近一次违规的时间
+	for cycle := 0; cycle < 4; cycle++ {
+		// 良好阶段：每 10 秒一次正面交互
+		rose := false
+		prev := rm.ComputeReputation("me", "attacker", nil, now)
+		for step := 0; step < 30; step++ {
+			now = now.Add(10 * time.Second)
+			rm.AddInteraction(Interaction{From: "me", To: "attacker", PosEvents: 3, Timestamp: now, CommQuality: 0.9})
+			got := rm.ComputeReputation("me", "attacker", nil, now)
+
+			if ceiling := math.Pow(cfg.MaxRecovery, float64(severe)); got > ceiling+eps {
+				t.Errorf("cycle %d step %d: reputation %v above ceiling %v after %d severe offences", cycle, step, got, ceiling, severe)
+			}
+			if severe > 0 {
+				if limit := floor + cfg.RedemptionRate*now.Sub(offenceAt).Seconds(); got > limit+eps {
+					t.Errorf("cycle %d step %d: reputation %v recovered faster than %v", cycle, step, got, limit)
+				}
+			}
+			rose = rose || got > prev+eps
+			prev = got
+		}
+		if !rose {
+			t.Errorf("cycle %d: reputation never recovered during the good phase", cycle)
+		}
+
+		// 攻击阶段：一次严重违规，信誉立即下降
+		before := rm.ComputeReputation("me", "attacker", nil, now)
+		now = now.Add(10 * time.Second)
+		rm.AddInteraction(Interaction{From: "me", To: "attacker", NegEvents: 150, Timestamp: now, CommQuality: 0.9})
+		severe++
+		floor = rm.ComputeReputation("me", "attacker", nil, now)
+		offenceAt = now
+		if floor >= before {
+			t.Errorf("cycle %d: reputation %v did not drop below %v right after the offence", cycle, floor, before)
+		}
+		if ceiling := math.Pow(cfg.MaxRecovery, float64(severe)); floor > ceiling+eps {
+			t.Errorf("cycle %d: reputation %v above ceiling %v right after the offence", cycle, floor, ceiling)
+		}
+	}
+}
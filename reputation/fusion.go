@@ -0,0 +1,90 @@
+package reputation
+
+// 意见融合算子名称，通过 config.Config.FusionOperator 选择
+const (
+	FusionLocal      = "local"      // 只使用本地意见（原有行为）
+	FusionCumulative = "cumulative" // 累积信念融合 CBF
+	FusionAveraging  = "averaging"  // 平均信念融合 ABF
+	FusionWeighted   = "weighted"   // 加权信念融合 WBF
+)
+
+// fuseOpinions 按算子名称融合两个意见，未知名称退化为只使用本地意见
+func fuseOpinions(operator string, a, b Opinion) Opinion {
+	switch operator {
+	case FusionCumulative:
+		return cumulativeFusion(a, b)
+	case FusionAveraging:
+		return averagingFusion(a, b)
+	case FusionWeighted:
+		return weightedFusion(a, b)
+	default:
+		return a
+	}
+}
+
+// cumulativeFusion 累积信念融合：两个来源的证据相互独立，证据量相加
+func cumulativeFusion(a, b Opinion) Opinion {
+	// 两个意见都是教条意见（u=0）时，按相同权重取平均
+	if a.Uncertainty == 0 && b.Uncertainty == 0 {
+		return dogmaticAverage(a, b)
+	}
+
+	// b = (b_A×u_B + b_B×u_A) / (u_A + u_B - u_A×u_B)
+	denom := a.Uncertainty + b.Uncertainty - a.Uncertainty*b.Uncertainty
+	return Opinion{
+		Belief:      (a.Belief*b.Uncertainty + b.Belief*a.Uncertainty) / denom,
+		Disbelief:   (a.Disbelief*b.Uncertainty + b.Disbelief*a.Uncertainty) / denom,
+		Uncertainty: a.Uncertainty * b.Uncertainty / denom,
+		// a = (a_A×u_B + a_B×u_A - (a_A+a_B)×u_A×u_B) / (u_A + u_B - 2×u_A×u_B)
+		BaseRate: weightedBaseRate(a, b, (1-a.Uncertainty)*b.Uncertainty, (1-b.Uncertainty)*a.Uncertainty),
+	}
+}
+
+// averagingFusion 平均信念融合：两个来源的证据相互依赖，证据量取平均
+func averagingFusion(a, b Opinion) Opinion {
+	if a.Uncertainty == 0 && b.Uncertainty == 0 {
+		return dogmaticAverage(a, b)
+	}
+
+	// b = (b_A×u_B + b_B×u_A) / (u_A + u_B)
+	denom := a.Uncertainty + b.Uncertainty
+	return Opinion{
+		Belief:      (a.Belief*b.Uncertainty + b.Belief*a.Uncertainty) / denom,
+		Disbelief:   (a.Disbelief*b.Uncertainty + b.Disbelief*a.Uncertainty) / denom,
+		Uncertainty: 2 * a.Uncertainty * b.Uncertainty / denom,
+		BaseRate:    (a.BaseRate + b.BaseRate) / 2,
+	}
+}
+
+// weightedFusion 加权信念融合：按各自的确定性 (1-u) 加权
+func weightedFusion(a, b Opinion) Opinion {
+	if a.Uncertainty == 0 && b.Uncertainty == 0 {
+		return dogmaticAverage(a, b)
+	}
+	// 两个意见都是空意见（u=1）时没有任何证据可用
+	if a.Uncertainty == 1 && b.Uncertainty == 1 {
+		return Opinion{Belief: 0, Disbelief: 0, Uncertainty: 1, BaseRate: (a.BaseRate + b.BaseRate) / 2}
+	}
+
+	// b = (b_A×(1-u_A)×u_B + b_B×(1-u_B)×u_A) / (u_A + u_B - 2×u_A×u_B)
+	denom := a.Uncertainty + b.Uncertainty - 2*a.Uncertainty*b.Uncertainty
+	wa := (1 - a.Uncertainty) * b.Uncertainty
+	wb := (1 - b.Uncertainty) * a.Uncertainty
+	return Opinion{
+		Belief:      (a.Belief*wa + b.Belief*wb) / denom,
+		Disbelief:   (a.Disbelief*wa + b.Disbelief*wb) / denom,
+		Uncertainty: (2 - a.Uncertainty - b.Uncertainty) * a.Uncertainty * b.Uncertainty / denom,
+		// a = (a_A×(1-u_A) + a_B×(1-u_B)) / (2 - u_A - u_B)
+		BaseRate: weightedBaseRate(a, b, 1-a.Uncertainty, 1-b.Uncertainty),
+	}
+}
+
+// dogmaticAverage 两个教条意见（u=0）的极限情况：等权平均
+func dogmaticAverage(a, b Opinion) Opinion {
+	return Opinion{
+		Belief:      (a.Belief + b.Belief) / 2,
+		Disbelief:   (a.Disbelief + b.Disbelief) / 2,
+		Uncertainty: 0,
+		BaseRate:    (a.BaseRate + b.BaseRate) / 2,
+	}
+}
@@ -0,0 +1,57 @@
+package reputation
+
+import "testing"
+
+// TestFuseOpinions 各融合算子与手工计算的结果一致，包括空意见 (u=1) 与教条意见 (u=0)
+func TestFuseOpinions(t *testing.T) {
+	a := Opinion{Belief: 0.6, Disbelief: 0.2, Uncertainty: 0.2, BaseRate: 0.4}
+	b := Opinion{Belief: 0.3, Disbelief: 0.3, Uncertainty: 0.4, BaseRate: 0.8}
+	vacuous := Opinion{Uncertainty: 1, BaseRate: 0.9}
+	vacuous2 := Opinion{Uncertainty: 1, BaseRate: 0.5}
+	dogmatic := Opinion{Belief: 0.8, Disbelief: 0.2, BaseRate: 0.5}
+	dogmatic2 := Opinion{Belief: 0.4, Disbelief: 0.6, BaseRate: 0.7}
+
+	tests := []struct {
+		name     string
+		operator string
+		a, b     Opinion
+		want     Opinion
+	}{
+		{"local", FusionLocal, a, b, a},
+
+		// CBF 等价于证据相加：(6, 2) + (1.5, 1.5) = (7.5, 3.5)
+		{"cbf", FusionCumulative, a, b, Opinion{7.5 / 13, 3.5 / 13, 2.0 / 13, 0.224 / 0.44}},
+		{"cbf vacuous", FusionCumulative, a, vacuous, a},
+		{"cbf both vacuous", FusionCumulative, vacuous2, vacuous, Opinion{0, 0, 1, 0.7}},
+		{"cbf dogmatic", FusionCumulative, dogmatic, a, Opinion{0.8, 0.2, 0, 0.5}},
+		{"cbf both dogmatic", FusionCumulative, dogmatic, dogmatic2, Opinion{0.6, 0.4, 0, 0.6}},
+
+		{"abf", FusionAveraging, a, b, Opinion{0.5, 0.14 / 0.6, 0.16 / 0.6, 0.6}},
+		{"abf vacuous", FusionAveraging, a, vacuous, Opinion{0.5, 0.2 / 1.2, 0.4 / 1.2, 0.65}},
+		{"abf both vacuous", FusionAveraging, vacuous2, vacuous, Opinion{0, 0, 1, 0.7}},
+		{"abf dogmatic", FusionAveraging, dogmatic, a, Opinion{0.8, 0.2, 0, 0.45}},
+		{"abf both dogmatic", FusionAveraging, dogmatic, dogmatic2, Opinion{0.6, 0.4, 0, 0.6}},
+
+		{"wbf", FusionWeighted, a, b, Opinion{0.228 / 0.44, 0.1 / 0.44, 0.112 / 0.44, 0.8 / 1.4}},
+		{"wbf vacuous", FusionWeighted, a, vacuous, a},
+		{"wbf both vacuous", FusionWeighted, vacuous2, vacuous, Opinion{0, 0, 1, 0.7}},
+		{"wbf dogmatic", FusionWeighted, dogmatic, a, Opinion{0.8, 0.2, 0, 0.82 / 1.8}},
+		{"wbf both dogmatic", FusionWeighted, dogmatic, dogmatic2, Opinion{0.6, 0.4, 0, 0.6}},
+	}
+	for _, tt := range tests {
+		got := fuseOpinions(tt.operator, tt.a, tt.b)
+		if !closeTo(got.Belief, tt.want.Belief) || !closeTo(got.Disbelief, tt.want.Disbelief) ||
+			!closeTo(got.Uncertainty, tt.want.Uncertainty) || !closeTo(got.BaseRate, tt.want.BaseRate) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+		if sum := got.Belief + got.Disbelief + got.Uncertainty; !closeTo(sum, 1) {
+			t.Errorf("%s: b+d+u = %v", tt.name, sum)
+		}
+		// 融合是对称的
+		if tt.operator != FusionLocal {
+			if rev := fuseOpinions(tt.operator, tt.b, tt.a); !closeTo(rev.Belief, got.Belief) || !closeTo(rev.BaseRate, got.BaseRate) {
+				t.Errorf("%s: not commutative: %+v vs %+v", tt.name, rev, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package reputation
+
+import (
+	"math"
+	"time"
+)
+
+// defaultConfidenceLevel 可信区间的默认置信水平
+const defaultConfidenceLevel = 0.95
+
+// ReputationInterval 信誉的点估计与直接证据的可信区间 Beta(α+W×a, β+W×(1-a))。
+// 同为 0.7 的信誉，证据越多区间越窄
+type ReputationInterval struct {
+	Reputation float64 `json:"reputation"` // 点估计 T
+	Lower      float64 `json:"lower"`      // 可信区间下界
+	Upper      float64 `json:"upper"`      // 可信区间上界
+	Confidence float64 `json:"confidence"` // 置信水平
+	Alpha      float64 `json:"alpha"`      // 直接证据 α（时效性与上下文加权后）
+	Beta       float64 `json:"beta"`       // 直接证据 β
+}
+
+// ComputeReputationInterval 返回 ComputeReputation 的点估计及其可信区间
+func (rm *ReputationManager) ComputeReputationInterval(myID, target string, neighbors []string, now time.Time) ReputationInterval {
+	return rm.ComputeContextReputationInterval(myID, target, ContextAny, neighbors, now)
+}
+
+// ComputeContextReputationInterval 返回 ComputeContextReputation 的点估计及其可信区间
+func (rm *ReputationManager) ComputeContextReputationInterval(myID, target, context string, neighbors []string, now time.Time) ReputationInterval {
+	reputation, _, _, _ := rm.computeContextReputationDebug(myID, target, context, neighbors, now)
+	return rm.reputationInterval(target, context, reputation, now)
+}
+
+// reputationInterval 返回点估计 reputation 与直接证据 α、β 的可信区间。
+// 区间宽度反映本节点直接证据的多少；与 finalReputation 相同受考察期上限与宽恕模型恢复上限约束，
+// 并保证 Lower ≤ Reputation ≤ Upper，按下界排序不会比按点估计更乐观
+func (rm *ReputationManager) reputationInterval(target, context string, reputation float64, now time.Time) ReputationInterval {
+	rm.mu.RLock()
+	alpha, beta := rm.directEvidence(target, context, now)
+	baseRate := rm.baseRate(target)
+	rm.mu.RUnlock()
+
+	level := positiveOr(rm.cfg.ConfidenceLevel, defaultConfidenceLevel)
+	lower, upper := credibleInterval(alpha, beta, baseRate, level)
+	limit := rm.reputationLimit(target, rm.newcomerStatus(target, now), now)
+	lower = math.Min(math.Min(lower, limit), reputation)
+	upper = math.Max(math.Min(upper, limit), reputation)
+	return ReputationInterval{
+		Reputation: reputation,
+		Lower:      lower,
+		Upper:      upper,
+		Confidence: level,
+		Alpha:      alpha,
+		Beta:       beta,
+	}
+}
+
+// credibleInterval 返回证据 α、β 与基础率 a 对应的 Beta 分布的等尾可信区间：
+// Beta(α+W×a, β+W×(1-a))，取 (1-level)/2 与 (1+level)/2 分位数
+func credibleInterval(alpha, beta, baseRate, level float64) (lower, upper float64) {
+	a := math.Max(math.Max(alpha, 0)+priorWeight*baseRate, 1e-3)
+	b := math.Max(math.Max(beta, 0)+priorWeight*(1-baseRate), 1e-3)
+	level = math.Min(level, 1)
+	return betaQuantile((1-level)/2, a, b), betaQuantile((1+level)/2, a, b)
+}
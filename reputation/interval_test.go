@@ -0,0 +1,62 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+)
+
+// TestIntervalNarrowsWithEvidence 正负证据比例相同时，证据越多可信区间越窄，且区间包含点估计
+func TestIntervalNarrowsWithEvidence(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	rm := NewReputationManager(testConfig())
+	rm.AddInteraction(Interaction{From: "me", To: "few", PosEvents: 3, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+	for k := 0; k < 10; k++ {
+		rm.AddInteraction(Interaction{From: "me", To: "many", PosEvents: 3, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+	}
+
+	few := rm.ComputeReputationInterval("me", "few", nil, now)
+	many := rm.ComputeReputationInterval("me", "many", nil, now)
+	if few.Upper-few.Lower <= many.Upper-many.Lower {
+		t.Errorf("interval with 4 events [%v, %v] is not wider than with 40 events [%v, %v]", few.Lower, few.Upper, many.Lower, many.Upper)
+	}
+	for _, ri := range []ReputationInterval{few, many} {
+		if ri.Lower > ri.Reputation || ri.Reputation > ri.Upper {
+			t.Errorf("interval [%v, %v] does not contain the point estimate %v", ri.Lower, ri.Upper, ri.Reputation)
+		}
+	}
+}
+
+// TestLowerBoundRespectsLimits 被宽恕模型限制信誉的节点，其下界同样受限，按下界排序不会排在稳定节点之前
+func TestLowerBoundRespectsLimits(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	cfg := testConfig()
+	cfg.RedemptionRate = 0.0005
+	rm := NewReputationManager(cfg)
+
+	// capped 的直接证据绝大多数为正面，但发生过 4 次严重违规，恢复上限为 0.8^4
+	for k := 0; k < 30; k++ {
+		rm.AddInteraction(Interaction{From: "me", To: "capped", PosEvents: 10, Timestamp: now, CommQuality: 0.9})
+	}
+	for k := 1; k <= 4; k++ {
+		rm.AddInteraction(Interaction{From: "me", To: "capped", NegEvents: 5, Timestamp: now.Add(time.Duration(k) * time.Second), CommQuality: 0.9})
+	}
+	for k := 0; k < 50; k++ {
+		rm.AddInteraction(Interaction{From: "me", To: "steady", PosEvents: 3, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+	}
+
+	at := now.Add(5 * time.Second)
+	candidates := []string{"capped", "steady"}
+	greedy := rm.RankProviders("me", candidates, nil, at, SelectionOptions{Policy: PolicyGreedy})
+	if greedy[0].ID != "steady" {
+		t.Fatalf("greedy ranked %s first, want steady: %+v", greedy[0].ID, greedy)
+	}
+	lower := rm.RankProviders("me", candidates, nil, at, SelectionOptions{Policy: PolicyLowerBound})
+	if lower[0].ID != "steady" {
+		t.Errorf("lower-bound ranked %s first, more optimistic than greedy: %+v", lower[0].ID, lower)
+	}
+	for _, ps := range lower {
+		if ps.Lower > ps.Reputation {
+			t.Errorf("%s: lower bound %v above reputation %v", ps.ID, ps.Lower, ps.Reputation)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package reputation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrustModel 信任模型接口，便于在同一交互流上对比不同模型
+type TrustModel interface {
+	// Name 返回模型名称
+	Name() string
+	// Ingest 接收一次交互记录
+	Ingest(inter Interaction)
+	// Score 计算 requester 眼中 target 的信任值 ∈ [0,1]
+	Score(requester, target string, neighbors []string, now time.Time) float64
+	// Explain 以 JSON 说明信任值的计算过程，结构为 ModelExplanation
+	Explain(requester, target string, neighbors []string, now time.Time) string
+}
+
+// ModelExplanation 各模型 Explain 输出的统一 JSON 结构，便于比较不同模型的结果
+type ModelExplanation struct {
+	Model  string      `json:"model"`
+	Score  float64     `json:"score"`
+	Detail interface{} `json:"detail"` // 模型相关的中间量
+}
+
+// explainJSON 将模型的计算过程序列化为 ModelExplanation JSON
+func explainJSON(model string, score float64, detail interface{}) string {
+	data, err := json.Marshal(ModelExplanation{Model: model, Score: score, Detail: detail})
+	if err != nil {
+		return fmt.Sprintf(`{"model":%q,"error":%q}`, model, err.Error())
+	}
+	return string(data)
+}
+
+// 信任模型名称，通过 config.Config.TrustModel 选择
+const (
+	ModelSubjectiveLogic = "subjective" // 论文的主观逻辑模型 (b, d, u)
+	ModelBeta            = "beta"       // Beta 信誉系统 BRS
+	ModelEigenTrust      = "eigentrust" // EigenTrust（在邻居子图上迭代）
+	ModelPeerTrust       = "peertrust"  // PeerTrust（满意度 × 评价者可信度）
+	ModelDirichlet       = "dirichlet"  // 多项意见（Dirichlet 分布，按评价等级的效用投影为标量）
+)
+
+// trustModels 模型名称到构造函数的映射，owner 用于访问配置与邻居节点
+var trustModels = map[string]func(owner *ReputationManager) TrustModel{
+	ModelSubjectiveLogic: func(owner *ReputationManager) TrustModel { return owner },
+	ModelBeta:            func(owner *ReputationManager) TrustModel { return newBetaModel(owner) },
+	ModelEigenTrust:      func(owner *ReputationManager) TrustModel { return newEigenTrustModel(owner) },
+	ModelPeerTrust:       func(owner *ReputationManager) TrustModel { return newPeerTrustModel(owner) },
+	ModelDirichlet:       func(owner *ReputationManager) TrustModel { return newDirichletModel(owner) },
+}
+
+// TrustModelNames 返回所有可用的模型名称
+func TrustModelNames() []string {
+	names := make([]string, 0, len(trustModels))
+	for name := range trustModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTrustModel 判断模型名称是否可用
+func IsTrustModel(name string) bool {
+	_, ok := trustModels[name]
+	return ok
+}
+
+// newTrustModel 按名称创建模型，空名称或未知名称使用主观逻辑模型
+func newTrustModel(name string, owner *ReputationManager) TrustModel {
+	if ctor, ok := trustModels[name]; ok {
+		return ctor(owner)
+	}
+	return owner
+}
+
+// peerModel 返回进程内邻居节点当前使用的模型，调用方按类型断言取出同类模型。
+// 其他模型需要邻居的原始评价数据，因此只能使用进程内的邻居。
+func peerModel(owner *ReputationManager, id string) TrustModel {
+	src, ok := owner.lookupPeer(id)
+	if !ok {
+		return nil
+	}
+	peer, ok := src.(*ReputationManager)
+	if !ok {
+		return nil
+	}
+	return peer.Model()
+}
+
+// ============ 主观逻辑模型：ReputationManager 本身 ============
+
+// Name 实现 TrustModel
+func (rm *ReputationManager) Name() string {
+	return ModelSubjectiveLogic
+}
+
+// Ingest 实现 TrustModel，等价于 AddInteraction
+func (rm *ReputationManager) Ingest(inter Interaction) {
+	rm.AddInteraction(inter)
+}
+
+// Score 实现 TrustModel，等价于 ComputeReputation
+func (rm *ReputationManager) Score(requester, target string, neighbors []string, now time.Time) float64 {
+	return rm.ComputeReputation(requester, target, neighbors, now)
+}
+
+// Explain 实现 TrustModel，detail 为 ExplainReputation 的结果
+func (rm *ReputationManager) Explain(requester, target string, neighbors []string, now time.Time) string {
+	b := rm.ExplainReputation(requester, target, neighbors, now)
+	return explainJSON(ModelSubjectiveLogic, b.Reputation, b)
+}
+
+// ============ Beta 信誉系统 BRS ============
+
+// betaModel 以 Beta(r+1, s+1) 的期望作为信任值，
+// 每次交互前先按遗忘因子 λ 衰减已有证据，推荐证据直接累加
+type betaModel struct {
+	owner  *ReputationManager
+	mu     sync.Mutex
+	counts map[string]*betaCounts // target -> (r, s)
+}
+
+type betaCounts struct {
+	r, s float64
+}
+
+func newBetaModel(owner *ReputationManager) *betaModel {
+	return &betaModel{owner: owner, counts: make(map[string]*betaCounts)}
+}
+
+func (m *betaModel) Name() string { return ModelBeta }
+
+func (m *betaModel) Ingest(inter Interaction) {
+	forgetting := m.owner.cfg.BetaForgetting
+	if forgetting <= 0 || forgetting > 1 {
+		forgetting = 1
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.counts[inter.To]
+	if !ok {
+		c = &betaCounts{}
+		m.counts[inter.To] = c
+	}
+	pos, neg := m.owner.interactionEvidence(inter)
+	c.r = forgetting*c.r + pos
+	c.s = forgetting*c.s + neg
+}
+
+// evidence 返回本节点对 target 的 (r, s)
+func (m *betaModel) evidence(target string) (r, s float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.counts[target]; ok {
+		return c.r, c.s
+	}
+	return 0, 0
+}
+
+// combined 汇总本节点与邻居节点的证据
+func (m *betaModel) combined(target string, neighbors []string) (r, s float64, used []string) {
+	r, s = m.evidence(target)
+	for _, id := range neighbors {
+		peer, ok := peerModel(m.owner, id).(*betaModel)
+		if !ok || id == target {
+			continue
+		}
+		pr, ps := peer.evidence(target)
+		if pr+ps == 0 {
+			continue
+		}
+		r += pr
+		s += ps
+		used = append(used, id)
+	}
+	return r, s, used
+}
+
+func (m *betaModel) Score(requester, target string, neighbors []string, now time.Time) float64 {
+	r, s, _ := m.combined(target, neighbors)
+	// E[Beta(r+1, s+1)] = (r+1) / (r+s+2)
+	return (r + 1) / (r + s + 2)
+}
+
+func (m *betaModel) Explain(requester, target string, neighbors []string, now time.Time) string {
+	r, s, used := m.combined(target, neighbors)
+	return explainJSON(ModelBeta, (r+1)/(r+s+2), struct {
+		R            float64  `json:"r"`
+		S            float64  `json:"s"`
+		Recommenders []string `json:"recommenders"`
+	}{r, s, used})
+}
+
+// ============ EigenTrust ============
+
+// eigenTrustModel 在 {requester} ∪ neighbors ∪ {target} 组成的子图上迭代
+// t = (1-a)·Cᵀ·t + a·p，预信任向量 p 集中在 requester 上（个性化 EigenTrust），
+// 结果按子图中的最大值归一化到 [0,1]
+type eigenTrustModel struct {
+	owner *ReputationManager
+	mu    sync.Mutex
+	local map[string]float64 // target -> s_ij = sat - unsat
+}
+
+const (
+	eigenTrustMaxIter = 100
+	eigenTrustEpsilon = 1e-9
+)
+
+func newEigenTrustModel(owner *ReputationManager) *eigenTrustModel {
+	return &eigenTrustModel{owner: owner, local: make(map[string]float64)}
+}
+
+func (m *eigenTrustModel) Name() string { return ModelEigenTrust }
+
+func (m *eigenTrustModel) Ingest(inter Interaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pos, neg := m.owner.interactionEvidence(inter)
+	m.local[inter.To] += pos - neg
+}
+
+// row 返回归一化后的本地信任 c_ij（只保留 members 中的节点）
+func (m *eigenTrustModel) row(self string, members []string) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	row := make([]float64, len(members))
+	var sum float64
+	for k, id := range members {
+		if id == self {
+			continue
+		}
+		row[k] = math.Max(m.local[id], 0)
+		sum += row[k]
+	}
+	if sum == 0 {
+		return nil
+	}
+	for k := range row {
+		row[k] /= sum
+	}
+	return row
+}
+
+// globalTrust 返回子图成员及其全局信任值
+func (m *eigenTrustModel) globalTrust(requester, target string, neighbors []string) ([]string, []float64, int) {
+	members := []string{requester}
+	seen := map[string]bool{requester: true}
+	for _, id := range append(append([]string{}, neighbors...), target) {
+		if !seen[id] {
+			seen[id] = true
+			members = append(members, id)
+		}
+	}
+	n := len(members)
+
+	// 构造 C，本地信任全为 0 的节点按均匀分布信任其他成员
+	c := make([][]float64, n)
+	for i, id := range members {
+		if i == 0 {
+			c[i] = m.row(id, members)
+		} else if peer, ok := peerModel(m.owner, id).(*eigenTrustModel); ok {
+			c[i] = peer.row(id, members)
+		}
+		if c[i] == nil {
+			c[i] = make([]float64, n)
+			for k := range c[i] {
+				if k != i {
+					c[i][k] = 1 / float64(n-1)
+				}
+			}
+		}
+	}
+
+	a := m.owner.cfg.EigenTrustAlpha
+	if a <= 0 || a >= 1 {
+		a = 0.15
+	}
+	p := make([]float64, n)
+	p[0] = 1
+
+	t := append([]float64{}, p...)
+	iter := 0
+	for ; iter < eigenTrustMaxIter; iter++ {
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				next[j] += (1 - a) * c[i][j] * t[i]
+			}
+		}
+		var delta float64
+		for j := range next {
+			next[j] += a * p[j]
+			delta += math.Abs(next[j] - t[j])
+		}
+		t = next
+		if delta < eigenTrustEpsilon {
+			break
+		}
+	}
+	return members, t, iter
+}
+
+func (m *eigenTrustModel) Score(requester, target string, neighbors []string, now time.Time) float64 {
+	members, t, _ := m.globalTrust(requester, target, neighbors)
+	return normalizedTrust(members, t, requester, target)
+}
+
+func (m *eigenTrustModel) Explain(requester, target string, neighbors []string, now time.Time) string {
+	members, t, iter := m.globalTrust(requester, target, neighbors)
+	return explainJSON(ModelEigenTrust, normalizedTrust(members, t, requester, target), struct {
+		Members    []string  `json:"members"`
+		Trust      []float64 `json:"trust"`
+		Iterations int       `json:"iterations"`
+	}{members, t, iter})
+}
+
+// normalizedTrust 将 target 的全局信任按除 requester 外的最大值归一化
+func normalizedTrust(members []string, t []float64, requester, target string) float64 {
+	var maxT, targetT float64
+	for k, id := range members {
+		if id == requester {
+			continue
+		}
+		maxT = math.Max(maxT, t[k])
+		if id == target {
+			targetT = t[k]
+		}
+	}
+	if maxT == 0 {
+		return 0
+	}
+	return targetT / maxT
+}
+
+// ============ PeerTrust ============
+
+// peerTrustModel T(u) = Σ S(u,i)·Cr(v) / Σ Cr(v)·I(u,v)，
+// 满意度 S 取每次交互中正面事件的比例，评价者可信度 Cr(v) 取请求者对评价者的平均满意度
+type peerTrustModel struct {
+	owner *ReputationManager
+	mu    sync.Mutex
+	sat   map[string]*satisfaction // target -> 满意度累计
+}
+
+type satisfaction struct {
+	sum   float64
+	count float64
+}
+
+func newPeerTrustModel(owner *ReputationManager) *peerTrustModel {
+	return &peerTrustModel{owner: owner, sat: make(map[string]*satisfaction)}
+}
+
+func (m *peerTrustModel) Name() string { return ModelPeerTrust }
+
+func (m *peerTrustModel) Ingest(inter Interaction) {
+	pos, neg := m.owner.interactionEvidence(inter)
+	total := pos + neg
+	if total == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sat[inter.To]
+	if !ok {
+		s = &satisfaction{}
+		m.sat[inter.To] = s
+	}
+	s.sum += pos / total
+	s.count++
+}
+
+// satisfactionWith 返回本节点对 target 的满意度累计
+func (m *peerTrustModel) satisfactionWith(target string) satisfaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sat[target]; ok {
+		return *s
+	}
+	return satisfaction{}
+}
+
+// credibility 请求者对评价者的可信度，没有交互时取 γ
+func (m *peerTrustModel) credibility(rater string) float64 {
+	s := m.satisfactionWith(rater)
+	if s.count == 0 {
+		return m.owner.cfg.Gamma
+	}
+	return s.sum / s.count
+}
+
+func (m *peerTrustModel) trust(target string, neighbors []string) (float64, map[string]float64) {
+	own := m.satisfactionWith(target)
+	num, den := own.sum, own.count
+	creds := make(map[string]float64)
+	for _, id := range neighbors {
+		if id == target {
+			continue
+		}
+		peer, ok := peerModel(m.owner, id).(*peerTrustModel)
+		if !ok {
+			continue
+		}
+		s := peer.satisfactionWith(target)
+		if s.count == 0 {
+			continue
+		}
+		cr := m.credibility(id)
+		creds[id] = cr
+		num += cr * s.sum
+		den += cr * s.count
+	}
+	if den == 0 {
+		return m.owner.cfg.Gamma, creds
+	}
+	return num / den, creds
+}
+
+func (m *peerTrustModel) Score(requester, target string, neighbors []string, now time.Time) float64 {
+	t, _ := m.trust(target, neighbors)
+	return t
+}
+
+func (m *peerTrustModel) Explain(requester, target string, neighbors []string, now time.Time) string {
+	own := m.satisfactionWith(target)
+	t, creds := m.trust(target, neighbors)
+	return explainJSON(ModelPeerTrust, t, struct {
+		OwnSatisfaction float64            `json:"own_satisfaction"` // 本节点满意度之和
+		OwnCount        float64            `json:"own_count"`        // 本节点的交互次数
+		Credibility     map[string]float64 `json:"credibility"`      // 各评价者的可信度 Cr(v)
+	}{own.sum, own.count, creds})
+}
@@ -0,0 +1,37 @@
+package reputation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestExplainJSON 所有模型的 Explain 都返回 ModelExplanation JSON，且分值与 Score 一致
+func TestExplainJSON(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	for _, name := range TrustModelNames() {
+		cfg := testConfig()
+		cfg.TrustModel = name
+		a, b := NewReputationManager(cfg), NewReputationManager(cfg)
+		a.AddPeer("b", b)
+		a.AddInteraction(Interaction{From: "a", To: "p", PosEvents: 3, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+		b.AddInteraction(Interaction{From: "b", To: "p", PosEvents: 1, Timestamp: now, CommQuality: 0.9})
+		a.AddInteraction(Interaction{From: "a", To: "b", PosEvents: 2, Timestamp: now, CommQuality: 0.9})
+
+		model := a.Model()
+		var exp ModelExplanation
+		if err := json.Unmarshal([]byte(model.Explain("a", "p", []string{"b"}, now)), &exp); err != nil {
+			t.Errorf("%s: Explain is not JSON: %v", name, err)
+			continue
+		}
+		if exp.Model != name {
+			t.Errorf("%s: model = %q", name, exp.Model)
+		}
+		if score := model.Score("a", "p", []string{"b"}, now); !closeTo(exp.Score, score) {
+			t.Errorf("%s: explained score %v, Score %v", name, exp.Score, score)
+		}
+		if exp.Detail == nil {
+			t.Errorf("%s: missing detail", name)
+		}
+	}
+}
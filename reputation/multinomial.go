@@ -0,0 +1,231 @@
+package reputation
+
+import (
+	"block/config"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 默认的评价等级，从好到坏排列，可通过 config.Config.RatingLevels 修改
+const (
+	RatingAccurate    = "accurate"     // 数据准确
+	RatingSlightlyOff = "slightly_off" // 略有偏差
+	RatingStale       = "stale"        // 数据过时
+	RatingFabricated  = "fabricated"   // 伪造数据
+)
+
+var (
+	defaultRatingLevels  = []string{RatingAccurate, RatingSlightlyOff, RatingStale, RatingFabricated}
+	defaultRatingUtility = []float64{1, 0.7, 0.3, 0}
+)
+
+// MultinomialOpinion 多项意见，对应 Dirichlet 分布：
+// b_k = r_k/(W+Σr)，u = W/(W+Σr)，Σb_k + u = 1
+type MultinomialOpinion struct {
+	Belief      []float64 `json:"b"`
+	Uncertainty float64   `json:"u"`
+	BaseRate    []float64 `json:"a"` // 各等级的基础率，Σa_k = 1
+}
+
+// NewMultinomialOpinion 由各等级的证据 r_k 与基础率 a_k 构造多项意见
+func NewMultinomialOpinion(evidence, baseRate []float64) MultinomialOpinion {
+	total := priorWeight
+	for _, r := range evidence {
+		total += r
+	}
+	o := MultinomialOpinion{
+		Belief:      make([]float64, len(evidence)),
+		Uncertainty: priorWeight / total,
+		BaseRate:    append([]float64{}, baseRate...),
+	}
+	for k, r := range evidence {
+		o.Belief[k] = r / total
+	}
+	return o
+}
+
+// ProjectedProbabilities 各等级的投影概率 P_k = b_k + a_k×u
+func (o MultinomialOpinion) ProjectedProbabilities() []float64 {
+	p := make([]float64, len(o.Belief))
+	for k := range o.Belief {
+		p[k] = o.Belief[k] + o.BaseRate[k]*o.Uncertainty
+	}
+	return p
+}
+
+// Evidence 返回各等级的证据 r_k = W×b_k/u，教条意见 (u=0) 没有有限的证据量，返回 nil
+func (o MultinomialOpinion) Evidence() []float64 {
+	if o.Uncertainty == 0 {
+		return nil
+	}
+	r := make([]float64, len(o.Belief))
+	for k, b := range o.Belief {
+		r[k] = priorWeight * b / o.Uncertainty
+	}
+	return r
+}
+
+// Dirichlet 返回对应的 Dirichlet 参数 α_k = r_k + W×a_k
+func (o MultinomialOpinion) Dirichlet() []float64 {
+	r := o.Evidence()
+	if r == nil {
+		return nil
+	}
+	alpha := make([]float64, len(r))
+	for k := range r {
+		alpha[k] = r[k] + priorWeight*o.BaseRate[k]
+	}
+	return alpha
+}
+
+// Binomial 按各等级的效用 v_k ∈ [0,1]（与等级一一对应）投影为二项意见：
+// b = Σb_k×v_k，d = Σb_k×(1-v_k)，a = Σa_k×v_k，u 不变
+func (o MultinomialOpinion) Binomial(utility []float64) Opinion {
+	op := Opinion{Uncertainty: o.Uncertainty}
+	for k, b := range o.Belief {
+		op.Belief += b * utility[k]
+		op.Disbelief += b * (1 - utility[k])
+		op.BaseRate += o.BaseRate[k] * utility[k]
+	}
+	return op
+}
+
+// Expectation 按效用加权的期望信任值，即二项投影的投影概率
+func (o MultinomialOpinion) Expectation(utility []float64) float64 {
+	return o.Binomial(utility).ProjectedProbability()
+}
+
+// ratingScale 返回配置的评价等级与效用
+func (rm *ReputationManager) ratingScale() (levels []string, utility []float64) {
+	return ratingScaleOf(rm.cfg)
+}
+
+// ratingScaleOf 返回 cfg 中的评价等级与效用，配置缺失或长度不一致时使用默认值
+func ratingScaleOf(cfg config.Config) (levels []string, utility []float64) {
+	if len(cfg.RatingLevels) > 1 && len(cfg.RatingLevels) == len(cfg.RatingUtility) {
+		return cfg.RatingLevels, cfg.RatingUtility
+	}
+	return defaultRatingLevels, defaultRatingUtility
+}
+
+// validateRatings 检查评价向量：长度不超过评价等级数，各等级的次数非负
+func validateRatings(ratings []float64, levels int) error {
+	if len(ratings) > levels {
+		return fmt.Errorf("评价向量长度 %d 超过评价等级数 %d", len(ratings), levels)
+	}
+	for k, r := range ratings {
+		if !(r >= 0) {
+			return fmt.Errorf("评价等级 %d 的次数 %v 无效", k, r)
+		}
+	}
+	return nil
+}
+
+// interactionEvidence 返回一次交互按严重程度与评价效用折算的正、负证据
+func (rm *ReputationManager) interactionEvidence(inter Interaction) (pos, neg float64) {
+	_, utility := rm.ratingScale()
+	return eventEvidence(rm.cfg.EventSeverity, utility, inter)
+}
+
+// ratingEvidence 返回一次交互在各评价等级上的证据。
+// 没有评价向量时，按严重程度加权的正面证据计入最好的等级、负面证据计入最差的等级
+func ratingEvidence(severity map[string]float64, levels int, inter Interaction) []float64 {
+	r := make([]float64, levels)
+	if len(inter.Ratings) > 0 {
+		copy(r, inter.Ratings)
+		return r
+	}
+	pos, neg := eventEvidence(severity, nil, inter)
+	r[0] += pos
+	r[levels-1] += neg
+	return r
+}
+
+// ============ Dirichlet 多项信誉模型 ============
+
+// dirichletModel 按评价等级累积 Dirichlet 证据，推荐证据直接累加，
+// 以按效用加权的期望作为信任值
+type dirichletModel struct {
+	owner  *ReputationManager
+	mu     sync.Mutex
+	counts map[string][]float64 // target -> 各等级的证据
+}
+
+func newDirichletModel(owner *ReputationManager) *dirichletModel {
+	return &dirichletModel{owner: owner, counts: make(map[string][]float64)}
+}
+
+func (m *dirichletModel) Name() string { return ModelDirichlet }
+
+func (m *dirichletModel) Ingest(inter Interaction) {
+	levels, _ := m.owner.ratingScale()
+	r := ratingEvidence(m.owner.cfg.EventSeverity, len(levels), inter)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.counts[inter.To]
+	if !ok {
+		c = make([]float64, len(levels))
+		m.counts[inter.To] = c
+	}
+	for k := range c {
+		c[k] += r[k]
+	}
+}
+
+// evidence 返回本节点对 target 的各等级证据
+func (m *dirichletModel) evidence(target string, levels int) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := make([]float64, levels)
+	copy(r, m.counts[target])
+	return r
+}
+
+// Opinion 汇总本节点与邻居节点的证据，返回 requester 眼中 target 的多项意见
+func (m *dirichletModel) Opinion(target string, neighbors []string) (MultinomialOpinion, []string) {
+	levels, _ := m.owner.ratingScale()
+	r := m.evidence(target, len(levels))
+	var used []string
+	for _, id := range neighbors {
+		peer, ok := peerModel(m.owner, id).(*dirichletModel)
+		if !ok || id == target {
+			continue
+		}
+		pr := peer.evidence(target, len(levels))
+		var total float64
+		for k := range r {
+			r[k] += pr[k]
+			total += pr[k]
+		}
+		if total > 0 {
+			used = append(used, id)
+		}
+	}
+
+	// 各等级的基础率相同
+	baseRate := make([]float64, len(levels))
+	for k := range baseRate {
+		baseRate[k] = 1 / float64(len(levels))
+	}
+	return NewMultinomialOpinion(r, baseRate), used
+}
+
+func (m *dirichletModel) Score(requester, target string, neighbors []string, now time.Time) float64 {
+	op, _ := m.Opinion(target, neighbors)
+	_, utility := m.owner.ratingScale()
+	return op.Expectation(utility)
+}
+
+func (m *dirichletModel) Explain(requester, target string, neighbors []string, now time.Time) string {
+	op, used := m.Opinion(target, neighbors)
+	levels, utility := m.owner.ratingScale()
+	return explainJSON(ModelDirichlet, op.Expectation(utility), struct {
+		Levels       []string           `json:"levels"`
+		Utility      []float64          `json:"utility"`
+		Opinion      MultinomialOpinion `json:"opinion"`
+		Projected    []float64          `json:"projected"`
+		Recommenders []string           `json:"recommenders"`
+	}{levels, utility, op, op.ProjectedProbabilities(), used})
+}
@@ -0,0 +1,119 @@
+package reputation
+
+import (
+	"math"
+	"time"
+)
+
+// 新节点引导策略，通过 config.Config.NewcomerPolicy 选择
+const (
+	NewcomerNone    = "none"    // 不区分新节点，T = b + a×u（默认）
+	NewcomerMinimum = "minimum" // 考察期内的节点以 NewcomerBaseRate 代替基础率 a，且不高于已知节点中最低的本地信誉
+)
+
+// NewcomerVerifier 押金或身份验证钩子，返回节点 id 的担保程度 [0,1]：
+// 0 表示未验证，1 表示完全担保（按老节点对待）。
+// 担保程度按比例把基础率提升到节点类别的基础率、把考察期的信誉上限放宽到 1。
+type NewcomerVerifier func(id string) float64
+
+// NewcomerStatus 目标在新节点引导策略下的状态
+type NewcomerStatus struct {
+	Policy    string    `json:"policy"`
+	FirstSeen time.Time `json:"first_seen,omitempty"` // 本节点首次记录到目标的时间，从未见过时为零值
+	Probation bool      `json:"probation"`            // 是否处于考察期
+	BaseRate  float64   `json:"base_rate"`            // 计算信誉所用的基础率
+	Cap       float64   `json:"cap"`                  // 考察期内的信誉上限
+	Verified  float64   `json:"verified,omitempty"`   // 验证钩子给出的担保程度
+}
+
+// SetNewcomerVerifier 设置新节点的押金或身份验证钩子，nil 表示不验证
+func (rm *ReputationManager) SetNewcomerVerifier(v NewcomerVerifier) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.verifier = v
+}
+
+// newcomerPolicy 返回配置的引导策略
+func (rm *ReputationManager) newcomerPolicy() string {
+	if rm.cfg.NewcomerPolicy == "" {
+		return NewcomerNone
+	}
+	return rm.cfg.NewcomerPolicy
+}
+
+// noteSeen 记录节点首次出现的时间。该时间只会提前不会推后，
+// 同一 ID 离开后重新加入既不会清除已有证据，也不会重新开始考察期。调用方需持有 rm.mu 的写锁
+func (rm *ReputationManager) noteSeen(id string, ts time.Time) {
+	if first, ok := rm.firstSeen[id]; !ok || ts.Before(first) {
+		rm.firstSeen[id] = ts
+	}
+}
+
+// newcomerStatus 返回目标在引导策略下的状态
+func (rm *ReputationManager) newcomerStatus(target string, now time.Time) NewcomerStatus {
+	rm.mu.RLock()
+	first, seen := rm.firstSeen[target]
+	verifier := rm.verifier
+	baseRate := rm.baseRate(target)
+	rm.mu.RUnlock()
+
+	st := NewcomerStatus{Policy: rm.newcomerPolicy(), BaseRate: baseRate, Cap: 1}
+	if st.Policy == NewcomerNone {
+		return st
+	}
+	if seen {
+		st.FirstSeen = first
+	}
+
+	// 从未见过的节点同样处于考察期
+	st.Probation = !seen || now.Sub(first).Seconds() < rm.cfg.ProbationPeriod
+	if !st.Probation {
+		return st
+	}
+
+	// 基础率不高于已知节点中最低的本地信誉，换 ID 重新加入无法获得比最差节点更高的信任
+	st.BaseRate = math.Min(rm.cfg.NewcomerBaseRate, rm.lowestKnownReputation(target, now))
+	st.Cap = positiveOr(rm.cfg.ProbationCap, 1)
+	if verifier != nil {
+		st.Verified = math.Min(math.Max(verifier(target), 0), 1)
+		st.BaseRate += st.Verified * (baseRate - st.BaseRate)
+		st.Cap += st.Verified * (1 - st.Cap)
+	}
+	return st
+}
+
+// lowestKnownReputation 返回本节点有直接证据的其他目标中最低的本地信誉
+func (rm *ReputationManager) lowestKnownReputation(exclude string, now time.Time) float64 {
+	lowest := 1.0
+	for _, id := range rm.knownTargets() {
+		if id == exclude {
+			continue
+		}
+		lowest = math.Min(lowest, rm.opinionToReputation(rm.localOpinion(id, ContextAny, now)))
+	}
+	return lowest
+}
+
+// finalReputation 由融合意见计算最终信誉：
+// 考察期内以新节点基础率代替意见的基础率，并限制在考察期上限以内；
+// 有不良行为记录的目标不超过宽恕模型允许的恢复上限
+func (rm *ReputationManager) finalReputation(target string, op Opinion, now time.Time) (float64, NewcomerStatus) {
+	st := rm.newcomerStatus(target, now)
+	reputation := rm.opinionToReputation(op)
+	if st.Probation {
+		reputation = op.Belief + st.BaseRate*op.Uncertainty
+	}
+	return math.Min(reputation, rm.reputationLimit(target, st, now)), st
+}
+
+// reputationLimit 返回目标当前允许的最高信誉：考察期上限与宽恕模型恢复上限中的较小者
+func (rm *ReputationManager) reputationLimit(target string, st NewcomerStatus, now time.Time) float64 {
+	limit := 1.0
+	if st.Probation {
+		limit = st.Cap
+	}
+	if fs := rm.forgivenessStatus(target, now); fs != nil {
+		limit = math.Min(limit, fs.Limit)
+	}
+	return limit
+}
@@ -0,0 +1,27 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNewcomerNotAboveWorstKnown 考察期内换 ID 重新加入的节点，信誉不高于已知节点中最差的一个
+func TestNewcomerNotAboveWorstKnown(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	cfg := testConfig()
+	cfg.NewcomerPolicy = NewcomerMinimum
+	cfg.NewcomerBaseRate = 0.3
+	cfg.ProbationPeriod = 3600
+	rm := NewReputationManager(cfg)
+
+	rm.AddInteraction(Interaction{From: "me", To: "good", PosEvents: 10, Timestamp: now, CommQuality: 0.9})
+	rm.AddInteraction(Interaction{From: "me", To: "bad", NegEvents: 100, Timestamp: now, CommQuality: 0.9})
+
+	worst := rm.opinionToReputation(rm.localOpinion("bad", ContextAny, now))
+	if worst >= cfg.NewcomerBaseRate {
+		t.Fatalf("worst known reputation %v is not below the newcomer base rate", worst)
+	}
+	if got := rm.ComputeReputation("me", "rejoined", nil, now); got > worst+1e-9 {
+		t.Errorf("rejoining node reputation = %v, worst known = %v", got, worst)
+	}
+}
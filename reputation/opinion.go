@@ -0,0 +1,96 @@
+package reputation
+
+import (
+	"math"
+)
+
+// priorWeight 非信息先验权重 W，二项意见与 Beta 分布互换时使用
+const priorWeight = 2.0
+
+// 节点类别，不同类别的基础率通过 config.Config.NodeClassBaseRate 配置
+const (
+	NodeClassRSU = "rsu" // 路侧单元
+	NodeClassBus = "bus" // 公交车
+	NodeClassCar = "car" // 私家车
+)
+
+// ProjectedProbability 投影概率 P = b + a×u
+func (o Opinion) ProjectedProbability() float64 {
+	return o.Belief + o.BaseRate*o.Uncertainty
+}
+
+// Evidence 将意见换算为 Beta 证据 (r, s)：r = W×b/u，s = W×d/u。
+// 教条意见 (u=0) 的证据量为无穷大
+func (o Opinion) Evidence() (r, s float64) {
+	if o.Uncertainty == 0 {
+		return math.Inf(1), math.Inf(1)
+	}
+	return priorWeight * o.Belief / o.Uncertainty, priorWeight * o.Disbelief / o.Uncertainty
+}
+
+// Beta 返回意见对应的 Beta(α, β) 参数：α = r + W×a，β = s + W×(1-a)
+func (o Opinion) Beta() (alpha, beta float64) {
+	r, s := o.Evidence()
+	return r + priorWeight*o.BaseRate, s + priorWeight*(1-o.BaseRate)
+}
+
+// OpinionFromEvidence 由 Beta 证据 (r, s) 与基础率 a 构造意见：
+// b = r/(r+s+W)，d = s/(r+s+W)，u = W/(r+s+W)
+func OpinionFromEvidence(r, s, baseRate float64) Opinion {
+	r, s = math.Max(r, 0), math.Max(s, 0)
+	total := r + s + priorWeight
+	return Opinion{
+		Belief:      r / total,
+		Disbelief:   s / total,
+		Uncertainty: priorWeight / total,
+		BaseRate:    baseRate,
+	}
+}
+
+// OpinionFromBeta 由 Beta(α, β) 参数与基础率 a 构造意见，先验部分 W×a、W×(1-a) 之外的才是证据
+func OpinionFromBeta(alpha, beta, baseRate float64) Opinion {
+	return OpinionFromEvidence(alpha-priorWeight*baseRate, beta-priorWeight*(1-baseRate), baseRate)
+}
+
+// SetNodeClass 设置节点的类别（如 NodeClassRSU），该节点作为目标时使用类别对应的基础率
+func (rm *ReputationManager) SetNodeClass(id, class string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.classes[id] = class
+}
+
+// NodeClass 返回节点的类别，未设置时为空
+func (rm *ReputationManager) NodeClass(id string) string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.classes[id]
+}
+
+// nodeClassesLocked 返回节点类别的副本，调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) nodeClassesLocked() map[string]string {
+	if len(rm.classes) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(rm.classes))
+	for id, class := range rm.classes {
+		out[id] = class
+	}
+	return out
+}
+
+// baseRate 返回目标的基础率：按其类别查 cfg.NodeClassBaseRate，未配置时为 γ。
+// 调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) baseRate(target string) float64 {
+	if a, ok := rm.cfg.NodeClassBaseRate[rm.classes[target]]; ok {
+		return a
+	}
+	return rm.cfg.Gamma
+}
+
+// weightedBaseRate 按权重 wa、wb 融合两个意见的基础率，权重都为 0 时取平均
+func weightedBaseRate(a, b Opinion, wa, wb float64) float64 {
+	if wa+wb == 0 {
+		return (a.BaseRate + b.BaseRate) / 2
+	}
+	return (a.BaseRate*wa + b.BaseRate*wb) / (wa + wb)
+}
@@ -2,65 +2,209 @@ package reputation
 
 import (
 	"block/config"
+	"fmt"
+	"log"
 	"math"
-	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Vector 表示轨迹点（速度、位置、方向）
 type Vector struct {
-	Speed     float64
-	Location  float64 // 归一化位置 [0,1]
-	Direction float64 // 弧度
+	Speed     float64 `json:"speed"`
+	Location  float64 `json:"location"`       // 归一化纵向位置 [0,1]
+	Lateral   float64 `json:"lateral"`        // 横向位置（相对车道线的偏移，米）
+	Lane      int     `json:"lane,omitempty"` // 车道编号，0 表示未知
+	Direction float64 `json:"direction"`      // 弧度
 }
 
 // Interaction 表示一次交互事件
 type Interaction struct {
-	From         string    // 交互发起者（数据请求者）
-	To           string    // 交互接收者（数据提供者）
-	PosEvents    int       // 正面事件数量 α
-	NegEvents    int       // 负面事件数量 β
-	Timestamp    time.Time // 事件发生时间
-	CommQuality  float64   // 通信质量 s_{i→j} ∈ [0,1]
-	TrajUser     []Vector  // 请求者轨迹
-	TrajProvider []Vector  // 提供者轨迹
+	From         string    `json:"from"`              // 交互发起者（数据请求者）
+	To           string    `json:"to"`                // 交互接收者（数据提供者）
+	PosEvents    int       `json:"pos_events"`        // 正面事件数量 α（按 EventPositive 计）
+	NegEvents    int       `json:"neg_events"`        // 负面事件数量 β（按 EventNegative 计）
+	Events       []Event   `json:"events,omitempty"`  // 带类型的事件，按严重程度加权计入 α/β
+	Ratings      []float64 `json:"ratings,omitempty"` // 各评价等级的次数，与 RatingLevels 对应（多项意见）
+	Timestamp    time.Time `json:"timestamp"`         // 事件发生时间
+	CommQuality  float64   `json:"comm_quality"`      // 通信质量 s_{i→j} ∈ [0,1]
+	TrajUser     []Vector  `json:"traj_user"`         // 请求者轨迹
+	TrajProvider []Vector  `json:"traj_provider"`     // 提供者轨迹
+	Context      string    `json:"context,omitempty"` // 数据类别（如拥堵、停车），空值为通用上下文
 }
 
-// Opinion 主观逻辑的意见三元组
+// ContextAny 查询时不区分上下文，使用所有类别的证据
+const ContextAny = ""
+
+// 时间衰减模式，通过 config.Config.DecayMode 选择
+const (
+	DecayBucket      = "bucket"      // 近期/过去两段式权重 ζ/σ（论文原始模型）
+	DecayExponential = "exponential" // 按半衰期连续指数衰减
+)
+
+// 方向差异模式，通过 config.Config.DirectionMode 选择
+const (
+	DirectionMean    = "mean"    // 比较两条轨迹的圆周平均方向
+	DirectionAligned = "aligned" // 逐点对齐后比较方向
+)
+
+// 交互频率 IF 的归一化模式，通过 config.Config.IFNormalization 选择
+const (
+	IFRatio   = "ratio"   // IF = N_ij / N̄_i（论文公式4，默认，无上界）
+	IFMinMax  = "minmax"  // (N_ij - min) / (max - min)，按 i 的所有交互目标
+	IFSoftmax = "softmax" // exp(N_ij/N̄_i) / Σ_k exp(N_ik/N̄_i)
+	IFCapped  = "capped"  // min(N_ij/N̄_i, IFCap) / IFCap
+)
+
+// defaultIFCap capped 模式下 IF 比值的默认上限
+const defaultIFCap = 2.0
+
+// Opinion 主观逻辑的二项意见 (b, d, u, a)
 type Opinion struct {
-	Belief      float64 // b: 信任度
-	Disbelief   float64 // d: 不信任度
-	Uncertainty float64 // u: 不确定性
+	Belief      float64 `json:"b"` // b: 信任度
+	Disbelief   float64 `json:"d"` // d: 不信任度
+	Uncertainty float64 `json:"u"` // u: 不确定性
+	BaseRate    float64 `json:"a"` // a: 基础率，没有证据时的先验信任
 }
 
-// ReputationManager 管理信誉计算
+// ReputationManager 管理信誉计算，可被多个 goroutine 并发使用
 type ReputationManager struct {
 	cfg          config.Config
+	mu           sync.RWMutex // 保护 interactions 与 peers
 	interactions []Interaction
-	peers        map[string]*ReputationManager // 邻居节点的引用（用于推荐意见）
+	store        *evidenceStore                  // 按 (From, To) 索引的增量证据
+	peers        map[string]RecommendationSource // 邻居节点的推荐来源
+	model        TrustModel                      // 按 cfg.TrustModel 选择的信任模型
+	flagged      map[string]flaggedRecommender   // 共谋/女巫检测标记的推荐者
+	logger       *log.Logger                     // 记录推荐过滤等事件，为 nil 时不输出
+	compactAt    int                             // 记录数达到该值时按保留策略自动压缩
+	firstSeen    map[string]time.Time            // 各节点首次出现在交互记录中的时间
+	verifier     NewcomerVerifier                // 新节点的押金或身份验证钩子
+	offences     map[string]*offenceRecord       // 各目标的不良行为记录（宽恕模型）
+	classes      map[string]string               // 节点类别，决定作为目标时的基础率
+	trajectories trajectoryStore                 // 按车辆保存的带时间戳轨迹
+	selections   atomic.Int64                    // SelectOptimalProvider 的调用次数，用于派生随机种子
+}
+
+// ValidateConfig 检查配置中相互依赖的选项：信任模型名称、推荐传输方式与信任模型、保留策略与衰减模式
+func ValidateConfig(cfg config.Config) error {
+	model := cfg.TrustModel
+	if model == "" {
+		model = ModelSubjectiveLogic
+	}
+	if !IsTrustModel(model) {
+		return fmt.Errorf("未知的信任模型: %s (可选: %v)", model, TrustModelNames())
+	}
+	// 其他模型直接读取邻居的交互记录，只能使用进程内的推荐来源
+	if cfg.PeerTransport == TransportHTTP && model != ModelSubjectiveLogic {
+		return fmt.Errorf("信任模型 %s 不支持 http 推荐传输, 请使用 memory 或 subjective 模型", model)
+	}
+	return ValidateRetention(cfg)
+}
+
+// Config 返回管理器当前使用的配置（从快照恢复后为快照中的配置）
+func (rm *ReputationManager) Config() config.Config {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.cfg
 }
 
 // NewReputationManager 创建管理器
 func NewReputationManager(cfg config.Config) *ReputationManager {
-	return &ReputationManager{
-		cfg:   cfg,
-		peers: make(map[string]*ReputationManager),
+	rm := &ReputationManager{
+		cfg:          cfg,
+		store:        newEvidenceStore(cfg),
+		peers:        make(map[string]RecommendationSource),
+		firstSeen:    make(map[string]time.Time),
+		trajectories: make(trajectoryStore),
+		offences:     make(map[string]*offenceRecord),
+		classes:      make(map[string]string),
 	}
+	rm.model = newTrustModel(cfg.TrustModel, rm)
+	return rm
+}
+
+// Model 返回当前使用的信任模型
+func (rm *ReputationManager) Model() TrustModel {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.model
 }
 
-// AddInteraction 添加交互记录
+// AddInteraction 添加交互记录，评价向量无效的交互被拒绝并记录日志
 func (rm *ReputationManager) AddInteraction(inter Interaction) {
+	rm.mu.Lock()
+	levels, _ := rm.ratingScale()
+	if err := validateRatings(inter.Ratings, len(levels)); err != nil {
+		rm.mu.Unlock()
+		rm.logf("拒绝 %s→%s 的交互: %v", inter.From, inter.To, err)
+		return
+	}
 	rm.interactions = append(rm.interactions, inter)
+	rm.store.add(inter)
+	rm.noteSeen(inter.From, inter.Timestamp)
+	rm.noteSeen(inter.To, inter.Timestamp)
+	rm.noteOffence(inter)
+	var folded int
+	if rm.retentionEnabled() && len(rm.interactions) >= rm.compactAt {
+		folded = rm.compactLocked(inter.Timestamp)
+	}
+	kept := len(rm.interactions)
+	model := rm.model
+	rm.mu.Unlock()
+
+	if folded > 0 {
+		rm.logf("压缩交互历史: 折叠 %d 条记录，保留 %d 条", folded, kept)
+	}
+
+	// 其他信任模型维护各自的状态
+	if model != TrustModel(rm) {
+		model.Ingest(inter)
+	}
 }
 
-// AddPeer 添加邻居节点（用于推荐意见）
-func (rm *ReputationManager) AddPeer(id string, peer *ReputationManager) {
+// AddPeer 添加邻居节点（用于推荐意见），peer 可以是进程内的 *ReputationManager 或 HTTPSource
+func (rm *ReputationManager) AddPeer(id string, peer RecommendationSource) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
 	rm.peers[id] = peer
 }
 
-// GetInteractions 获取交互记录（用于调试）
+// SetLogger 设置日志输出
+func (rm *ReputationManager) SetLogger(logger *log.Logger) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.logger = logger
+}
+
+// logf 输出一条日志
+func (rm *ReputationManager) logf(format string, args ...interface{}) {
+	rm.mu.RLock()
+	logger := rm.logger
+	rm.mu.RUnlock()
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
+
+// GetInteractions 获取交互记录的副本（用于调试）
 func (rm *ReputationManager) GetInteractions() []Interaction {
-	return rm.interactions
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	out := make([]Interaction, len(rm.interactions))
+	copy(out, rm.interactions)
+	return out
+}
+
+// lookupPeer 在读锁下查找邻居节点。
+// 计算推荐意见时不能在持有自身锁的同时去锁邻居，否则两个节点互相查询时可能死锁，
+// 因此先取出邻居引用、释放自身锁，再由邻居自行加锁。
+func (rm *ReputationManager) lookupPeer(id string) (RecommendationSource, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	peer, ok := rm.peers[id]
+	return peer, ok && peer != nil
 }
 
 // ============ 公式1: 计算本地意见三元组 (b, d, u) ============
@@ -89,61 +233,56 @@ func (rm *ReputationManager) computeLocalOpinion(alpha, beta float64, commQualit
 	return Opinion{Belief: b, Disbelief: d, Uncertainty: u}
 }
 
-// ============ 公式2: 计算信誉值 T = b + γ×u ============
+// ============ 公式2: 计算信誉值 T = b + a×u ============
+// 论文中的 γ 即基础率 a，按目标的节点类别配置，未配置时为 γ
 func (rm *ReputationManager) opinionToReputation(op Opinion) float64 {
-	return op.Belief + rm.cfg.Gamma*op.Uncertainty
+	return op.ProjectedProbability()
 }
 
-// ============ 公式3-4: 计算交互频率权重 IF_{i→j} ============
-func (rm *ReputationManager) computeInteractionFrequency(from, to string, now time.Time) float64 {
-	// 统计 from 到 to 的交互次数（按时效性加权）
-	var recentPos, recentNeg, pastPos, pastNeg float64
-
-	for _, inter := range rm.interactions {
-		if inter.From != from || inter.To != to {
-			continue
-		}
-
-		deltaTime := now.Sub(inter.Timestamp).Seconds()
-		isRecent := deltaTime <= rm.cfg.TRecent
-
-		if isRecent {
-			recentPos += float64(inter.PosEvents)
-			recentNeg += float64(inter.NegEvents)
-		} else {
-			pastPos += float64(inter.PosEvents)
-			pastNeg += float64(inter.NegEvents)
-		}
+// ============ 公式3: 交互证据的时效性权重 ============
+// pairAlphaBeta 返回交互对按时效性加权后的 α、β。
+// bucket 模式按 TRecent 划分近期/过去，分别乘以 ζ/σ（论文原始模型）；
+// exponential 模式按半衰期连续衰减，正、负事件分别使用各自的衰减率。
+// 结果与逐条扫描记录 Σ w(Δt)×pos 相同，仅因求和顺序不同存在浮点舍入误差（见 store_test.go）。
+func (rm *ReputationManager) pairAlphaBeta(pe *pairEvidence, now time.Time) (alpha, beta float64) {
+	if rm.cfg.DecayMode == DecayExponential {
+		pos, neg := pe.decayed(now, rm.store.lambdaPos, rm.store.lambdaNeg)
+		return rm.cfg.Theta * pos, rm.cfg.Tau * neg
 	}
 
-	// 公式3: 应用时效性权重
-	alpha := rm.cfg.Zeta*rm.cfg.Theta*recentPos + rm.cfg.Sigma*rm.cfg.Theta*pastPos
-	beta := rm.cfg.Zeta*rm.cfg.Tau*recentNeg + rm.cfg.Sigma*rm.cfg.Tau*pastNeg
-	N_ij := alpha + beta
+	recentPos, recentNeg, pastPos, pastNeg := pe.splitRecent(now, rm.cfg.TRecent)
+	alpha = rm.cfg.Zeta*rm.cfg.Theta*recentPos + rm.cfg.Sigma*rm.cfg.Theta*pastPos
+	beta = rm.cfg.Zeta*rm.cfg.Tau*recentNeg + rm.cfg.Sigma*rm.cfg.Tau*pastNeg
+	return alpha, beta
+}
 
-	// 计算平均交互次数
-	counts := make(map[string]float64)
-	for _, inter := range rm.interactions {
-		if inter.From == from {
-			deltaTime := now.Sub(inter.Timestamp).Seconds()
-			isRecent := deltaTime <= rm.cfg.TRecent
-
-			var alpha_k, beta_k float64
-			if isRecent {
-				alpha_k = rm.cfg.Zeta * rm.cfg.Theta * float64(inter.PosEvents)
-				beta_k = rm.cfg.Zeta * rm.cfg.Tau * float64(inter.NegEvents)
-			} else {
-				alpha_k = rm.cfg.Sigma * rm.cfg.Theta * float64(inter.PosEvents)
-				beta_k = rm.cfg.Sigma * rm.cfg.Tau * float64(inter.NegEvents)
-			}
-			counts[inter.To] += alpha_k + beta_k
-		}
+// decayRate 返回衰减率 λ：优先使用直接配置的 λ，否则由半衰期换算 λ = ln2 / T½，
+// 两者都未配置时不衰减
+func decayRate(lambda, halfLife float64) float64 {
+	if lambda > 0 {
+		return lambda
 	}
+	if halfLife > 0 {
+		return math.Ln2 / halfLife
+	}
+	return 0
+}
 
+// ============ 公式3-4: 计算交互频率权重 IF_{i→j} ============
+// 调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) computeInteractionFrequency(from, to string, now time.Time) float64 {
+	// 公式3: from 到 to 的交互次数（按时效性加权）
+	// 各上下文的证据合并计算，平均值按不同目标的数量计算
 	var sumCount float64
-	for _, c := range counts {
-		sumCount += c
+	counts := make(map[string]float64)
+	for _, pe := range rm.store.byFrom[from] {
+		alpha_k, beta_k := rm.pairAlphaBeta(pe, now)
+		sumCount += alpha_k + beta_k
+		counts[pe.key.To] += alpha_k + beta_k
 	}
+	N_ij := counts[to]
+
+	// 计算平均交互次数
 	avgCount := 1.0
 	if len(counts) > 0 {
 		avgCount = sumCount / float64(len(counts))
@@ -153,7 +292,46 @@ func (rm *ReputationManager) computeInteractionFrequency(from, to string, now ti
 	if avgCount == 0 {
 		return 0
 	}
-	return N_ij / avgCount
+	return rm.normalizeInteractionFrequency(N_ij, avgCount, counts)
+}
+
+// normalizeInteractionFrequency 按 IFNormalization 将 N_ij 归一化。
+// 除 ratio 外各模式的结果都在 [0,1] 内，避免交互频繁的邻居在公式11中占绝对主导
+func (rm *ReputationManager) normalizeInteractionFrequency(n, avg float64, counts map[string]float64) float64 {
+	switch rm.cfg.IFNormalization {
+	case IFMinMax:
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, c := range counts {
+			lo, hi = math.Min(lo, c), math.Max(hi, c)
+		}
+		if hi == lo {
+			// 所有目标的交互次数相同
+			if n > 0 {
+				return 1
+			}
+			return 0
+		}
+		return math.Min(math.Max((n-lo)/(hi-lo), 0), 1)
+	case IFSoftmax:
+		if n == 0 {
+			return 0
+		}
+		// 减去最大值避免溢出
+		top := math.Inf(-1)
+		for _, c := range counts {
+			top = math.Max(top, c/avg)
+		}
+		var sum float64
+		for _, c := range counts {
+			sum += math.Exp(c/avg - top)
+		}
+		return math.Exp(n/avg-top) / sum
+	case IFCapped:
+		limit := positiveOr(rm.cfg.IFCap, defaultIFCap)
+		return math.Min(n/avg, limit) / limit
+	default:
+		return n / avg
+	}
 }
 
 // ============ 公式5-9: 计算轨迹相似度 SIM(L_i, L_j) ============
@@ -165,7 +343,7 @@ func (rm *ReputationManager) computeTrajectorySimilarity(trajUser, trajProvider
 	// 公式7: 速度差异
 	speedDiff := rm.computeSpeedDifference(trajUser, trajProvider)
 
-	// 公式8: 位置差异（基于LCS）
+	// 公式8: 位置差异（默认基于LCS，可通过 TrajectoryMetric 选择其他轨迹距离）
 	locationDiff := rm.computeLocationDifference(trajUser, trajProvider)
 
 	// 公式9: 方向差异
@@ -174,8 +352,8 @@ func (rm *ReputationManager) computeTrajectorySimilarity(trajUser, trajProvider
 	// 公式6: DISS = ψ₁×speed + ψ₂×location + ψ₃×direction
 	diss := rm.cfg.Psi1*speedDiff + rm.cfg.Psi2*locationDiff + rm.cfg.Psi3*directionDiff
 
-	// 公式5: SIM = 1 - DISS
-	return 1.0 - diss
+	// 公式5: SIM = 1 - DISS，ψ 之和大于 1 时 DISS 可能超过 1，截断到 [0,1] 避免出现负权重
+	return math.Min(math.Max(1.0-diss, 0), 1)
 }
 
 // 公式7: 速度差异
@@ -200,31 +378,13 @@ func (rm *ReputationManager) computeSpeedDifference(traj1, traj2 []Vector) float
 	return math.Abs(avgSpeed1-avgSpeed2) / maxSpeed
 }
 
-// 公式8: 位置差异（基于LCS最长公共子序列）
+// 公式8: 位置差异，由配置的轨迹距离度量给出，归一化到 [0,1]
 func (rm *ReputationManager) computeLocationDifference(traj1, traj2 []Vector) float64 {
-	// 提取位置序列
-	loc1 := make([]float64, len(traj1))
-	loc2 := make([]float64, len(traj2))
-	for i, v := range traj1 {
-		loc1[i] = v.Location
-	}
-	for i, v := range traj2 {
-		loc2[i] = v.Location
-	}
-
-	// 计算LCS长度
-	lcsLen := rm.computeLCS(loc1, loc2)
-
-	maxLen := math.Max(float64(len(loc1)), float64(len(loc2)))
-	if maxLen == 0 {
-		return 0
-	}
-
-	return (maxLen - float64(lcsLen)) / maxLen
+	return rm.trajectoryMetric().Distance(traj1, traj2)
 }
 
-// LCS算法实现
-func (rm *ReputationManager) computeLCS(seq1, seq2 []float64) int {
+// LCS算法实现，两个轨迹点在二维位置上足够接近时视为匹配
+func (rm *ReputationManager) computeLCS(seq1, seq2 []Vector) int {
 	m, n := len(seq1), len(seq2)
 	if m == 0 || n == 0 {
 		return 0
@@ -236,10 +396,9 @@ func (rm *ReputationManager) computeLCS(seq1, seq2 []float64) int {
 		dp[i] = make([]int, n+1)
 	}
 
-	threshold := 0.05 // 位置相似阈值
 	for i := 1; i <= m; i++ {
 		for j := 1; j <= n; j++ {
-			if math.Abs(seq1[i-1]-seq2[j-1]) < threshold {
+			if rm.colocated(seq1[i-1], seq2[j-1]) {
 				dp[i][j] = dp[i-1][j-1] + 1
 			} else {
 				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
@@ -250,31 +409,88 @@ func (rm *ReputationManager) computeLCS(seq1, seq2 []float64) int {
 	return dp[m][n]
 }
 
+// colocated 判断两个轨迹点是否位于同一位置。
+// 已知车道且车道不同时直接判定为不同位置；否则要求纵向、横向偏移落在以
+// LocationThreshold、LateralThreshold 为半轴的椭圆内。LateralThreshold 为 0 时只比较纵向位置。
+func (rm *ReputationManager) colocated(p, q Vector) bool {
+	if p.Lane != 0 && q.Lane != 0 && p.Lane != q.Lane {
+		return false
+	}
+
+	threshold := rm.cfg.LocationThreshold
+	if threshold <= 0 {
+		threshold = 0.05 // 位置相似阈值
+	}
+	dx := (p.Location - q.Location) / threshold
+	if rm.cfg.LateralThreshold <= 0 {
+		return math.Abs(dx) < 1
+	}
+
+	dy := (p.Lateral - q.Lateral) / rm.cfg.LateralThreshold
+	return dx*dx+dy*dy < 1
+}
+
 // 公式9: 方向差异
 func (rm *ReputationManager) computeDirectionDifference(traj1, traj2 []Vector) float64 {
 	if len(traj1) == 0 || len(traj2) == 0 {
 		return 0
 	}
 
-	// 计算平均方向
-	var avgDir1, avgDir2 float64
-	for _, v := range traj1 {
-		avgDir1 += v.Direction
-	}
-	for _, v := range traj2 {
-		avgDir2 += v.Direction
+	if rm.cfg.DirectionMode == DirectionAligned {
+		return alignedDirectionDifference(traj1, traj2)
 	}
-	avgDir1 /= float64(len(traj1))
-	avgDir2 /= float64(len(traj2))
+
+	// 计算圆周平均方向，航向在 ±π 附近时算术平均会得到相反的方向
+	avgDir1, r1 := circularMean(traj1)
+	avgDir2, r2 := circularMean(traj2)
 
 	// 计算方向夹角 φ
-	phi := math.Abs(avgDir1 - avgDir2)
-	// 归一化到 [0, π]
+	phi := angleBetween(avgDir1, avgDir2)
+
+	// 圆周方差 V = 1-R 越大平均方向越不可靠，平均方向的差异按 R1×R2 计入；
+	// 两条轨迹的离散程度不同本身也是差异，单独计入 |V1-V2|。
+	// 两项之和不超过 1，完全相同的轨迹无论方向是否分散，差异都为 0
+	return r1*r2*directionPenalty(phi) + math.Abs(r1-r2)
+}
+
+// alignedDirectionDifference 按相对位置逐点对齐两条轨迹，取各点方向差异的平均值
+func alignedDirectionDifference(traj1, traj2 []Vector) float64 {
+	n := len(traj1)
+	if len(traj2) > n {
+		n = len(traj2)
+	}
+
+	var sum float64
+	for k := 0; k < n; k++ {
+		i := k * len(traj1) / n
+		j := k * len(traj2) / n
+		sum += directionPenalty(angleBetween(traj1[i].Direction, traj2[j].Direction))
+	}
+	return sum / float64(n)
+}
+
+// circularMean 返回航向的圆周均值与平均合成向量长度 R ∈ [0,1]，圆周方差为 1-R
+func circularMean(traj []Vector) (mean, r float64) {
+	var sumSin, sumCos float64
+	for _, v := range traj {
+		sumSin += math.Sin(v.Direction)
+		sumCos += math.Cos(v.Direction)
+	}
+	n := float64(len(traj))
+	return math.Atan2(sumSin, sumCos), math.Hypot(sumSin, sumCos) / n
+}
+
+// angleBetween 返回两个航向之间的夹角，归一化到 [0, π]
+func angleBetween(a, b float64) float64 {
+	phi := math.Mod(math.Abs(a-b), 2*math.Pi)
 	if phi > math.Pi {
 		phi = 2*math.Pi - phi
 	}
+	return phi
+}
 
-	// 公式9: 分段函数
+// 公式9: 分段函数
+func directionPenalty(phi float64) float64 {
 	if phi <= math.Pi/4 {
 		return math.Sin(phi)
 	}
@@ -282,51 +498,76 @@ func (rm *ReputationManager) computeDirectionDifference(traj1, traj2 []Vector) f
 }
 
 // ============ 公式10: 计算整合权重 δ_{i→j} ============
-func (rm *ReputationManager) computeWeight(from, to string, trajUser, trajProvider []Vector, now time.Time) float64 {
+// 返回 δ 以及组成 δ 的 IF 与 SIM
+func (rm *ReputationManager) computeWeight(from, to string, trajUser, trajProvider []Vector, now time.Time) (weight, interFreq, trajSim float64) {
 	// IF_{i→j}: 交互频率
-	interFreq := rm.computeInteractionFrequency(from, to, now)
+	interFreq = rm.computeInteractionFrequency(from, to, now)
 
 	// SIM(L_i, L_j): 轨迹相似度
-	trajSim := rm.computeTrajectorySimilarity(trajUser, trajProvider)
+	trajSim = rm.computeTrajectorySimilarity(trajUser, trajProvider)
 
 	// δ_{i→j} = ρ₁×IF_{i→j} + ρ₂×SIM(L_i, L_j)
-	return rm.cfg.Rho1*interFreq + rm.cfg.Rho2*trajSim
+	return rm.cfg.Rho1*interFreq + rm.cfg.Rho2*trajSim, interFreq, trajSim
 }
 
 // ============ 公式11: 计算推荐意见 ============
-func (rm *ReputationManager) computeRecommendedOpinion(target string, neighbors []string, now time.Time) Opinion {
-	var bSum, dSum, uSum, weightSum float64
+// local 为请求者的本地意见，用于过滤不诚实推荐
+func (rm *ReputationManager) computeRecommendedOpinion(myID, target, context string, local Opinion, neighbors []string, now time.Time) Opinion {
+	contributions := rm.collectRecommendations(myID, target, context, neighbors, now)
+	rm.filterRecommendations(target, local, contributions)
+	return aggregateRecommendations(contributions, local.BaseRate)
+}
 
+// collectRecommendations 通过推荐来源收集各邻居对目标的意见及其权重。
+// 邻居没有目标的直接证据且 cfg.MaxHops > 1 时，邻居会返回沿邻居图得到的传递意见。
+func (rm *ReputationManager) collectRecommendations(myID, target, context string, neighbors []string, now time.Time) []NeighborContribution {
+	var contributions []NeighborContribution
 	for _, neighborID := range neighbors {
-		peer, exists := rm.peers[neighborID]
+		peer, exists := rm.lookupPeer(neighborID)
 		if !exists {
 			continue
 		}
 
-		// 获取邻居对目标的本地意见
-		neighborOpinion := peer.computeDirectOpinion(target, now)
+		// 获取邻居对目标的本地意见及权重 δ_{x→j}
+		rec, err := peer.Recommend(RecommendationQuery{
+			Recommender: neighborID,
+			Target:      target,
+			Context:     context,
+			Hops:        rm.maxHops(),
+			Visited:     []string{myID, neighborID, target},
+			Now:         now,
+		})
+		if err != nil {
+			rm.logf("获取邻居 %s 的推荐失败: %v", neighborID, err)
+			continue
+		}
+		c := NeighborContribution{ID: neighborID, Recommendation: rec}
 
-		// 计算权重 δ_{x→j}
-		var neighborTraj, targetTraj []Vector
-		for _, inter := range peer.interactions {
-			if inter.From == neighborID && inter.To == target {
-				neighborTraj = inter.TrajUser
-				targetTraj = inter.TrajProvider
-				break
-			}
+		// 被检测为共谋或女巫身份的推荐者降低权重
+		if penalty, reason := rm.recommenderFlag(neighborID); reason != "" {
+			c.Weight *= penalty
+			c.Flag = reason
 		}
+		contributions = append(contributions, c)
+	}
+	return contributions
+}
 
-		weight := peer.computeWeight(neighborID, target, neighborTraj, targetTraj, now)
+// aggregateRecommendations 公式11: 按 δ 加权平均邻居意见，没有可用推荐时返回基础率为 prior 的空意见
+func aggregateRecommendations(contributions []NeighborContribution, prior float64) Opinion {
+	var bSum, dSum, uSum, aSum, weightSum float64
 
+	for _, c := range contributions {
 		// 累加加权意见
-		bSum += weight * neighborOpinion.Belief
-		dSum += weight * neighborOpinion.Disbelief
-		uSum += weight * neighborOpinion.Uncertainty
-		weightSum += weight
+		bSum += c.Weight * c.Opinion.Belief
+		dSum += c.Weight * c.Opinion.Disbelief
+		uSum += c.Weight * c.Opinion.Uncertainty
+		aSum += c.Weight * c.Opinion.BaseRate
+		weightSum += c.Weight
 	}
 
 	if weightSum == 0 {
-		return Opinion{Belief: 0, Disbelief: 0, Uncertainty: 1}
+		return Opinion{Belief: 0, Disbelief: 0, Uncertainty: 1, BaseRate: prior}
 	}
 
 	// 归一化
@@ -334,100 +575,154 @@ func (rm *ReputationManager) computeRecommendedOpinion(target string, neighbors
 		Belief:      bSum / weightSum,
 		Disbelief:   dSum / weightSum,
 		Uncertainty: uSum / weightSum,
+		BaseRate:    aSum / weightSum,
 	}
 }
 
-// ============ 计算直接意见（本地意见）============
-func (rm *ReputationManager) computeDirectOpinion(target string, now time.Time) Opinion {
-	var totalAlpha, totalBeta float64
-	var avgCommQuality float64
-	count := 0
+// directRecommendation 在本节点的读锁下计算 recommenderID（即本节点）对目标的本地意见与整合权重 δ_{x→j}
+func (rm *ReputationManager) directRecommendation(recommenderID, target, context string, now time.Time) Recommendation {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
 
-	for _, inter := range rm.interactions {
-		if inter.To != target {
-			continue
-		}
+	neighborOpinion := rm.computeDirectOpinion(target, context, now)
 
-		deltaTime := now.Sub(inter.Timestamp).Seconds()
-		isRecent := deltaTime <= rm.cfg.TRecent
+	neighborTraj, targetTraj := rm.pairTrajectories(recommenderID, target, now)
 
-		if isRecent {
-			totalAlpha += rm.cfg.Zeta * rm.cfg.Theta * float64(inter.PosEvents)
-			totalBeta += rm.cfg.Zeta * rm.cfg.Tau * float64(inter.NegEvents)
-		} else {
-			totalAlpha += rm.cfg.Sigma * rm.cfg.Theta * float64(inter.PosEvents)
-			totalBeta += rm.cfg.Sigma * rm.cfg.Tau * float64(inter.NegEvents)
+	weight, interFreq, trajSim := rm.computeWeight(recommenderID, target, neighborTraj, targetTraj, now)
+	var interactions int
+	for _, pe := range rm.store.byTo[target] {
+		if rm.contextWeight(pe.key.Context, context) > 0 {
+			interactions += pe.count()
 		}
+	}
+
+	return Recommendation{
+		Opinion:              neighborOpinion,
+		Interactions:         interactions,
+		InteractionFreq:      interFreq,
+		TrajectorySimilarity: trajSim,
+		Weight:               weight,
+		Trajectory:           summarizeTrajectories(neighborTraj, targetTraj),
+	}
+}
+
+// ============ 计算直接意见（本地意见）============
+// 只使用 context 上下文的证据，其他上下文的证据按 cfg.ContextSimilarity 折算；
+// context 为 ContextAny 时使用全部证据。调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) computeDirectOpinion(target, context string, now time.Time) Opinion {
+	totalAlpha, totalBeta := rm.directEvidence(target, context, now)
+
+	// 启用新节点引导策略时，没有任何证据的陌生节点使用空意见，信誉即为基础率
+	if totalAlpha+totalBeta == 0 && rm.newcomerPolicy() != NewcomerNone {
+		return Opinion{Belief: 0, Disbelief: 0, Uncertainty: 1, BaseRate: rm.baseRate(target)}
+	}
+
+	op := rm.computeLocalOpinion(totalAlpha, totalBeta, rm.commQuality(target, context))
+	op.BaseRate = rm.baseRate(target)
+	return op
+}
 
-		avgCommQuality += inter.CommQuality
-		count++
+// directEvidence 返回本节点对目标按时效性与上下文加权后的 α、β，调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) directEvidence(target, context string, now time.Time) (alpha, beta float64) {
+	for _, pe := range rm.store.byTo[target] {
+		w := rm.contextWeight(pe.key.Context, context)
+		if w == 0 {
+			continue
+		}
+		a, b := rm.pairAlphaBeta(pe, now)
+		alpha += w * a
+		beta += w * b
 	}
+	return alpha, beta
+}
 
-	if count > 0 {
-		avgCommQuality /= float64(count)
-	} else {
-		avgCommQuality = 0.5 // 默认值
+// commQuality 返回本节点与目标的平均通信质量，与 directEvidence 相同按 contextWeight 加权，
+// 没有任何记录时为 0.5。调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) commQuality(target, context string) float64 {
+	var sum, count float64
+	for _, pe := range rm.store.byTo[target] {
+		w := rm.contextWeight(pe.key.Context, context)
+		sum += w * pe.commSum
+		count += w * float64(pe.commCount)
+	}
+	if count == 0 {
+		return 0.5
 	}
+	return sum / count
+}
+
+// localOpinion 在读锁下计算本节点对目标的直接意见
+func (rm *ReputationManager) localOpinion(target, context string, now time.Time) Opinion {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.computeDirectOpinion(target, context, now)
+}
 
-	return rm.computeLocalOpinion(totalAlpha, totalBeta, avgCommQuality)
+// contextWeight 返回 evidence 上下文的证据在查询 query 上下文时的权重
+func (rm *ReputationManager) contextWeight(evidence, query string) float64 {
+	if query == ContextAny || evidence == query {
+		return 1
+	}
+	return math.Min(math.Max(rm.cfg.ContextSimilarity, 0), 1)
 }
 
 // ============ 公式12-13: 融合本地与推荐意见 ============
 func (rm *ReputationManager) combineOpinions(local, recommended Opinion) Opinion {
-	// 为了让恶意节点信誉持续下降、诚实节点持续上升
-	// 只使用本地意见，不考虑推荐意见的稀释作用
-	// 这样正面/负面事件的影响会更直接、更明显
-	return local
+	// 融合算子由配置选择，默认 "local" 只使用本地意见，
+	// 不考虑推荐意见的稀释作用，便于与论文公式的融合结果对比
+	return fuseOpinions(rm.cfg.FusionOperator, local, recommended)
 }
 
 // ============ 公式14: 计算最终信誉并选择最优数据提供者 ============
 func (rm *ReputationManager) ComputeReputation(myID, target string, neighbors []string, now time.Time) float64 {
+	return rm.ComputeContextReputation(myID, target, ContextAny, neighbors, now)
+}
+
+// ComputeContextReputation 计算目标在 context 上下文（数据类别）中的信誉
+func (rm *ReputationManager) ComputeContextReputation(myID, target, context string, neighbors []string, now time.Time) float64 {
 	// 1. 计算本地意见
-	localOpinion := rm.computeDirectOpinion(target, now)
+	localOpinion := rm.localOpinion(target, context, now)
 
 	// 2. 计算推荐意见
-	recommendedOpinion := rm.computeRecommendedOpinion(target, neighbors, now)
+	recommendedOpinion := rm.computeRecommendedOpinion(myID, target, context, localOpinion, neighbors, now)
 
 	// 3. 融合意见
 	finalOpinion := rm.combineOpinions(localOpinion, recommendedOpinion)
 
-	// 4. 计算最终信誉值 T^final = b^final + γ×u^final
-	return rm.opinionToReputation(finalOpinion)
+	// 4. 计算最终信誉值 T^final = b^final + γ×u^final（考察期内的新节点按引导策略计算）
+	reputation, _ := rm.finalReputation(target, finalOpinion, now)
+	return reputation
 }
 
 // ============ 调试版本 ============
 func (rm *ReputationManager) ComputeReputationDebug(myID, target string, neighbors []string, now time.Time) (float64, Opinion, Opinion, Opinion) {
-	localOpinion := rm.computeDirectOpinion(target, now)
-	recommendedOpinion := rm.computeRecommendedOpinion(target, neighbors, now)
+	return rm.computeContextReputationDebug(myID, target, ContextAny, neighbors, now)
+}
+
+func (rm *ReputationManager) computeContextReputationDebug(myID, target, context string, neighbors []string, now time.Time) (float64, Opinion, Opinion, Opinion) {
+	localOpinion := rm.localOpinion(target, context, now)
+	recommendedOpinion := rm.computeRecommendedOpinion(myID, target, context, localOpinion, neighbors, now)
 	finalOpinion := rm.combineOpinions(localOpinion, recommendedOpinion)
-	reputation := rm.opinionToReputation(finalOpinion)
+	reputation, _ := rm.finalReputation(target, finalOpinion, now)
 	return reputation, localOpinion, recommendedOpinion, finalOpinion
 }
 
 // ============ 选择最优数据提供者 ============
-func (rm *ReputationManager) SelectOptimalProvider(myID string, candidates []string, neighbors []string, now time.Time) string {
-	if len(candidates) == 0 {
+// context 为所请求的数据类别，ContextAny 表示不区分
+func (rm *ReputationManager) SelectOptimalProvider(myID, context string, candidates []string, neighbors []string, now time.Time) string {
+	// 按配置的策略排序（默认按信誉值降序），返回排在首位的提供者。
+	// 每次调用使用不同的种子，随机策略才会真正探索
+	ranked := rm.RankProviders(myID, candidates, neighbors, now, SelectionOptions{
+		Policy:    rm.cfg.SelectionPolicy,
+		Epsilon:   rm.cfg.SelectionEpsilon,
+		UCBWeight: rm.cfg.SelectionUCBWeight,
+		Seed:      rm.cfg.SelectionSeed + rm.selections.Add(1) - 1,
+		Context:   context,
+	})
+	if len(ranked) == 0 {
 		return ""
 	}
-
-	type candidateScore struct {
-		id         string
-		reputation float64
-	}
-
-	var scores []candidateScore
-	for _, candidate := range candidates {
-		rep := rm.ComputeReputation(myID, candidate, neighbors, now)
-		scores = append(scores, candidateScore{id: candidate, reputation: rep})
-	}
-
-	// 按信誉值降序排序
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].reputation > scores[j].reputation
-	})
-
-	// 返回信誉值最高的提供者
-	return scores[0].id
+	return ranked[0].ID
 }
 
 // 辅助函数
@@ -0,0 +1,189 @@
+package reputation
+
+import (
+	"block/config"
+	"errors"
+	"math"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// minCompactBatch 自动压缩的最小间隔（记录数）
+const minCompactBatch = 64
+
+// HistoryStats 交互历史的记录数与内存统计
+type HistoryStats struct {
+	Records          int `json:"records"`           // 保留的交互记录数
+	FoldedRecords    int `json:"folded_records"`    // 已压缩为聚合证据的记录数
+	Pairs            int `json:"pairs"`             // 交互对 (From, To) 数量
+	TrajectoryPoints int `json:"trajectory_points"` // 保留的轨迹点数量
+	ApproxBytes      int `json:"approx_bytes"`      // 交互记录与轨迹占用内存的估计值
+}
+
+// HistoryStats 返回当前交互历史的统计信息
+func (rm *ReputationManager) HistoryStats() HistoryStats {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	st := HistoryStats{Records: len(rm.interactions), Pairs: len(rm.store.pairs)}
+	for _, inter := range rm.interactions {
+		st.TrajectoryPoints += len(inter.TrajUser) + len(inter.TrajProvider)
+	}
+	st.TrajectoryPoints += rm.trajectories.points()
+	var stamps int
+	for _, pe := range rm.store.pairs {
+		st.FoldedRecords += pe.folded.Count
+		stamps += len(pe.times)
+	}
+
+	st.ApproxBytes = st.Records*int(unsafe.Sizeof(Interaction{})) +
+		st.TrajectoryPoints*int(unsafe.Sizeof(Vector{})) +
+		stamps*(int(unsafe.Sizeof(time.Time{}))+2*int(unsafe.Sizeof(float64(0)))) +
+		st.Pairs*int(unsafe.Sizeof(pairEvidence{}))
+	return st
+}
+
+// retentionEnabled 是否配置了保留策略
+func (rm *ReputationManager) retentionEnabled() bool {
+	return rm.cfg.RetentionMaxAge > 0 || rm.cfg.RetentionMaxRecords > 0
+}
+
+// ValidateRetention 检查保留策略与衰减模式的组合。
+// bucket 模式下 TRecent 内的记录不能压缩，RetentionMaxRecords 无法对近期的交互生效，因此不允许同时配置
+func ValidateRetention(cfg config.Config) error {
+	if cfg.RetentionMaxRecords > 0 && cfg.DecayMode != DecayExponential {
+		return errors.New("retention_max_records 仅支持 exponential 衰减模式")
+	}
+	return nil
+}
+
+// Compact 按保留策略压缩交互历史：早于 RetentionMaxAge 的记录、
+// 以及每个交互对超出 RetentionMaxRecords 的最旧记录被折叠为聚合证据，
+// 其 α/β 与衰减后的贡献保持不变，但不再保留原始记录与轨迹。
+// 配置了保留策略时 AddInteraction 会自动调用，记录数每增长一倍压缩一次。
+func (rm *ReputationManager) Compact(now time.Time) {
+	rm.mu.Lock()
+	folded := rm.compactLocked(now)
+	kept := len(rm.interactions)
+	rm.mu.Unlock()
+
+	if folded > 0 {
+		rm.logf("压缩交互历史: 折叠 %d 条记录，保留 %d 条", folded, kept)
+	}
+}
+
+// compactLocked 执行压缩并返回被折叠的记录数，调用方需持有 rm.mu 的写锁
+func (rm *ReputationManager) compactLocked(now time.Time) int {
+	defer func() {
+		rm.compactAt = max(2*len(rm.interactions), minCompactBatch)
+	}()
+	if !rm.retentionEnabled() {
+		return 0
+	}
+
+	// 每个交互对的记录按时间排序（同一时间按插入顺序），与 pairEvidence.times 一致
+	groups := make(map[pairKey][]int)
+	for i, inter := range rm.interactions {
+		key := pairKey{From: inter.From, To: inter.To, Context: inter.Context}
+		groups[key] = append(groups[key], i)
+	}
+
+	drop := make([]bool, len(rm.interactions))
+	folds := make(map[pairKey]FoldedEvidence, len(groups))
+	var dropped int
+	for key, idx := range groups {
+		pe := rm.store.pairs[key]
+		k := rm.foldCount(pe, now)
+		if k == 0 {
+			continue
+		}
+		sort.SliceStable(idx, func(a, b int) bool {
+			return rm.interactions[idx[a]].Timestamp.Before(rm.interactions[idx[b]].Timestamp)
+		})
+
+		folded := make([]Interaction, k)
+		for n, i := range idx[:k] {
+			drop[i] = true
+			folded[n] = rm.interactions[i]
+		}
+		folds[key] = rm.store.fold(pe.folded, folded)
+		dropped += k
+	}
+	if dropped == 0 {
+		return 0
+	}
+
+	// 以新的切片保存保留的记录，释放被压缩记录的轨迹
+	kept := make([]Interaction, 0, len(rm.interactions)-dropped)
+	for i, inter := range rm.interactions {
+		if !drop[i] {
+			kept = append(kept, inter)
+		}
+	}
+
+	// 与快照恢复相同的方式重建索引，保证压缩后再保存、恢复的结果一致
+	store := newEvidenceStore(rm.cfg)
+	for _, key := range rm.store.keys {
+		f, ok := folds[key]
+		if !ok {
+			f = rm.store.pairs[key].folded
+		}
+		store.addFolded(f)
+	}
+	for _, inter := range kept {
+		store.add(inter)
+	}
+
+	rm.interactions = kept
+	rm.store = store
+	return dropped
+}
+
+// foldCount 返回交互对中需要压缩的最旧记录数。
+// bucket 模式下仍处于 TRecent 内的记录不压缩，保证近期/过去的划分不变（见 ValidateRetention）。
+func (rm *ReputationManager) foldCount(pe *pairEvidence, now time.Time) int {
+	n := len(pe.times)
+	olderThan := func(age float64) int {
+		return sort.Search(n, func(i int) bool { return now.Sub(pe.times[i]).Seconds() <= age })
+	}
+
+	var k int
+	if rm.cfg.RetentionMaxAge > 0 {
+		k = olderThan(rm.cfg.RetentionMaxAge)
+	}
+	if rm.cfg.RetentionMaxRecords > 0 && n-rm.cfg.RetentionMaxRecords > k {
+		k = n - rm.cfg.RetentionMaxRecords
+	}
+	if rm.cfg.DecayMode != DecayExponential {
+		if limit := olderThan(rm.cfg.TRecent); k > limit {
+			k = limit
+		}
+	}
+	return k
+}
+
+// fold 将按时间升序排列的记录累加到已有的聚合证据中
+func (s *evidenceStore) fold(f FoldedEvidence, records []Interaction) FoldedEvidence {
+	if f.Count == 0 {
+		f.Until = records[0].Timestamp
+	}
+
+	until := records[len(records)-1].Timestamp
+	dt := until.Sub(f.Until).Seconds()
+	f.DecayPos *= math.Exp(-s.lambdaPos * dt)
+	f.DecayNeg *= math.Exp(-s.lambdaNeg * dt)
+	f.Until = until
+
+	for _, inter := range records {
+		pos, neg := eventEvidence(s.severity, s.utility, inter)
+		dt := until.Sub(inter.Timestamp).Seconds()
+		f.Pos += pos
+		f.Neg += neg
+		f.DecayPos += pos * math.Exp(-s.lambdaPos*dt)
+		f.DecayNeg += neg * math.Exp(-s.lambdaNeg*dt)
+		f.CommSum += inter.CommQuality
+		f.Count++
+	}
+	return f
+}
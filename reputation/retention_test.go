@@ -0,0 +1,76 @@
+package reputation
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// TestCompactionPreservesEvidence 压缩后的 α、β、交互频率与信誉与保留全部记录时一致，保存、恢复后仍一致
+func TestCompactionPreservesEvidence(t *testing.T) {
+	t0 := time.Unix(1_000_000, 0)
+	for _, mode := range []string{DecayBucket, DecayExponential} {
+		cfg := testConfig()
+		cfg.DecayMode = mode
+		cfg.HalfLifePos, cfg.HalfLifeNeg = 1500, 3000
+		full := NewReputationManager(cfg)
+
+		cfg.RetentionMaxAge = 2000
+		if mode == DecayExponential {
+			cfg.RetentionMaxRecords = 20
+		}
+		compacted := NewReputationManager(cfg)
+
+		rng := rand.New(rand.NewSource(2))
+		targets := []string{"a", "b", "c"}
+		for k := 0; k < 400; k++ {
+			inter := Interaction{
+				From:        "me",
+				To:          targets[rng.Intn(len(targets))],
+				PosEvents:   rng.Intn(4),
+				NegEvents:   rng.Intn(2),
+				Timestamp:   t0.Add(time.Duration(k*20+rng.Intn(20)) * time.Second),
+				CommQuality: 0.5 + rng.Float64()/2,
+			}
+			full.AddInteraction(inter)
+			compacted.AddInteraction(inter)
+		}
+		end := t0.Add(8000 * time.Second)
+		compacted.Compact(end)
+		if st := compacted.HistoryStats(); st.FoldedRecords == 0 || st.Records >= len(full.GetInteractions()) {
+			t.Fatalf("%s: nothing was compacted: %+v", mode, st)
+		}
+
+		var buf bytes.Buffer
+		if err := compacted.Save(&buf, SnapshotBinary); err != nil {
+			t.Fatal(err)
+		}
+		restored := NewReputationManager(testConfig())
+		if err := restored.Load(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, offset := range []time.Duration{0, 500 * time.Second, 5000 * time.Second} {
+			now := end.Add(offset)
+			for _, target := range targets {
+				wantA, wantB := full.directEvidence(target, ContextAny, now)
+				wantIF := full.computeInteractionFrequency("me", target, now)
+				wantT := full.ComputeReputation("me", target, nil, now)
+				for name, rm := range map[string]*ReputationManager{"compacted": compacted, "restored": restored} {
+					where := fmt.Sprintf("%s/%s now=end+%v target=%s", mode, name, offset, target)
+					if a, b := rm.directEvidence(target, ContextAny, now); !closeTo(a, wantA) || !closeTo(b, wantB) {
+						t.Errorf("%s: (α, β) = (%v, %v), want (%v, %v)", where, a, b, wantA, wantB)
+					}
+					if got := rm.computeInteractionFrequency("me", target, now); !closeTo(got, wantIF) {
+						t.Errorf("%s: IF = %v, want %v", where, got, wantIF)
+					}
+					if got := rm.ComputeReputation("me", target, nil, now); !closeTo(got, wantT) {
+						t.Errorf("%s: T = %v, want %v", where, got, wantT)
+					}
+				}
+			}
+		}
+	}
+}
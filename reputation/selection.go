@@ -0,0 +1,181 @@
+package reputation
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// 提供者选择策略，通过 SelectionOptions.Policy 选择
+const (
+	PolicyGreedy        = "greedy"         // 按信任值排序（默认）
+	PolicyEpsilonGreedy = "epsilon-greedy" // 以概率 ε 随机提前一个候选者
+	PolicyUCB           = "ucb"            // 信任值 + 探索奖励 c×sqrt(ln(N+1)/(n+1))
+	PolicyThompson      = "thompson"       // 从以信任值为均值的 Beta 分布中采样
+	PolicyLowerBound    = "lower-bound"    // 按可信区间下界排序，证据少的候选者排名靠后
+)
+
+// SelectionOptions 提供者选择参数
+type SelectionOptions struct {
+	TopK           int     // 返回前 K 个，0 表示全部
+	MinTrust       float64 // 最低信任值，低于该值的候选者被过滤
+	MaxUncertainty float64 // 最大不确定性，0 表示不限制（注意会过滤掉从未交互过的候选者）
+	Policy         string  // 选择策略，空值为 greedy
+	Epsilon        float64 // epsilon-greedy 的探索概率
+	UCBWeight      float64 // UCB 的探索系数 c，0 时取 1
+	Seed           int64   // 随机种子，相同种子与输入得到相同结果
+	Context        string  // 所请求的数据类别，ContextAny 表示不区分；只有主观逻辑模型区分上下文
+}
+
+// ProviderScore 候选提供者的评分
+type ProviderScore struct {
+	ID           string  `json:"id"`
+	Reputation   float64 `json:"reputation"`   // 信任模型给出的信任值
+	Uncertainty  float64 `json:"uncertainty"`  // 意见的不确定性 u
+	Lower        float64 `json:"lower"`        // 信任值可信区间的下界
+	Upper        float64 `json:"upper"`        // 信任值可信区间的上界
+	Interactions int     `json:"interactions"` // 本节点与其直接交互的次数
+	Score        float64 `json:"score"`        // 按策略排序所用的分值
+	Explored     bool    `json:"explored"`     // 是否因探索被提前
+}
+
+// RankProviders 按选择策略返回排好序的候选提供者。
+// 分值相同时不确定性低者优先，仍相同时按 ID 字典序，保证结果可复现。
+func (rm *ReputationManager) RankProviders(myID string, candidates, neighbors []string, now time.Time, opts SelectionOptions) []ProviderScore {
+	// 先按 ID 排序去重，使随机抽样与输入顺序无关
+	ids := append([]string{}, candidates...)
+	sort.Strings(ids)
+
+	model := rm.Model()
+	var scores []ProviderScore
+	for k, id := range ids {
+		if k > 0 && ids[k-1] == id {
+			continue
+		}
+
+		ps := ProviderScore{ID: id, Interactions: rm.directInteractions(id)}
+		if model == TrustModel(rm) {
+			rep, _, _, final := rm.computeContextReputationDebug(myID, id, opts.Context, neighbors, now)
+			ps.Reputation, ps.Uncertainty = rep, final.Uncertainty
+			interval := rm.reputationInterval(id, opts.Context, rep, now)
+			ps.Lower, ps.Upper = interval.Lower, interval.Upper
+		} else {
+			// 其他模型没有意见三元组，按 Beta 分布的不确定性 W/(n+W) (W=2) 估计，
+			// 可信区间以交互次数 n 按信任值拆分为 α、β，并以信任值作为基础率
+			ps.Reputation = model.Score(myID, id, neighbors, now)
+			ps.Uncertainty = 2 / float64(ps.Interactions+2)
+			n := float64(ps.Interactions)
+			ps.Lower, ps.Upper = credibleInterval(ps.Reputation*n, (1-ps.Reputation)*n, ps.Reputation, positiveOr(rm.cfg.ConfidenceLevel, defaultConfidenceLevel))
+		}
+
+		if ps.Reputation < opts.MinTrust {
+			continue
+		}
+		if opts.MaxUncertainty > 0 && ps.Uncertainty > opts.MaxUncertainty {
+			continue
+		}
+		scores = append(scores, ps)
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	switch opts.Policy {
+	case PolicyUCB:
+		c := opts.UCBWeight
+		if c == 0 {
+			c = 1
+		}
+		var total int
+		for _, ps := range scores {
+			total += ps.Interactions
+		}
+		for k := range scores {
+			n := float64(scores[k].Interactions)
+			scores[k].Score = scores[k].Reputation + c*math.Sqrt(math.Log(float64(total)+1)/(n+1))
+		}
+	case PolicyThompson:
+		for k := range scores {
+			// 证据量越多，采样越集中在信任值附近
+			strength := float64(scores[k].Interactions) + 2
+			scores[k].Score = sampleBeta(rng, scores[k].Reputation*strength, (1-scores[k].Reputation)*strength)
+		}
+	case PolicyLowerBound:
+		for k := range scores {
+			scores[k].Score = scores[k].Lower
+		}
+	default:
+		for k := range scores {
+			scores[k].Score = scores[k].Reputation
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		if scores[i].Uncertainty != scores[j].Uncertainty {
+			return scores[i].Uncertainty < scores[j].Uncertainty
+		}
+		return scores[i].ID < scores[j].ID
+	})
+
+	if opts.Policy == PolicyEpsilonGreedy && len(scores) > 1 && rng.Float64() < opts.Epsilon {
+		// 随机选中一个候选者提前到首位，其余保持原有顺序
+		k := rng.Intn(len(scores))
+		picked := scores[k]
+		picked.Explored = true
+		copy(scores[1:k+1], scores[:k])
+		scores[0] = picked
+	}
+
+	if opts.TopK > 0 && len(scores) > opts.TopK {
+		scores = scores[:opts.TopK]
+	}
+	return scores
+}
+
+// directInteractions 返回本节点记录中与 target 的直接交互次数
+func (rm *ReputationManager) directInteractions(target string) int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	var n int
+	for _, pe := range rm.store.byTo[target] {
+		n += pe.count()
+	}
+	return n
+}
+
+// sampleBeta 从 Beta(a, b) 中采样
+func sampleBeta(rng *rand.Rand, a, b float64) float64 {
+	x := sampleGamma(rng, math.Max(a, 1e-3))
+	y := sampleGamma(rng, math.Max(b, 1e-3))
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// sampleGamma 用 Marsaglia-Tsang 方法从 Gamma(shape, 1) 中采样
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Gamma(a) = Gamma(a+1) × U^(1/a)
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSelectOptimalProviderExplores 通过配置选择随机策略时，SelectOptimalProvider 的多次调用会探索不同的候选者，
+// 相同的种子得到相同的选择序列
+func TestSelectOptimalProviderExplores(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	candidates := []string{"good", "fair", "new"}
+
+	picks := func(policy string) []string {
+		cfg := testConfig()
+		cfg.SelectionPolicy = policy
+		cfg.SelectionEpsilon = 0.5
+		cfg.SelectionSeed = 7
+		rm := NewReputationManager(cfg)
+		for k := 0; k < 5; k++ {
+			rm.AddInteraction(Interaction{From: "me", To: "good", PosEvents: 3, Timestamp: now, CommQuality: 0.9})
+			rm.AddInteraction(Interaction{From: "me", To: "fair", PosEvents: 2, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+		}
+		var out []string
+		for k := 0; k < 50; k++ {
+			out = append(out, rm.SelectOptimalProvider("me", ContextAny, candidates, nil, now))
+		}
+		return out
+	}
+
+	for _, policy := range []string{PolicyEpsilonGreedy, PolicyThompson} {
+		first := picks(policy)
+		seen := make(map[string]bool)
+		for _, id := range first {
+			seen[id] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("%s: always picked %v, expected exploration", policy, first[0])
+		}
+
+		second := picks(policy)
+		for k := range first {
+			if first[k] != second[k] {
+				t.Errorf("%s: call %d picked %s then %s with the same seed", policy, k, first[k], second[k])
+				break
+			}
+		}
+	}
+
+	if got := picks(PolicyGreedy); got[0] != "good" || got[len(got)-1] != "good" {
+		t.Errorf("greedy picked %v, want good", got[0])
+	}
+}
@@ -0,0 +1,227 @@
+package reputation
+
+import (
+	"block/config"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotFormat 快照文件格式
+type SnapshotFormat int
+
+const (
+	SnapshotJSON   SnapshotFormat = iota // 可读的 JSON 格式
+	SnapshotBinary                       // 紧凑的二进制格式（魔数 + 版本号 + gob）
+)
+
+// snapshotVersion 当前快照格式版本，格式变化时递增
+// 版本 2 增加了压缩后的聚合证据 Compacted
+// 版本 3 增加了交互记录的上下文 Context
+// 版本 4 增加了交互记录的事件 Events
+// 版本 5 增加了不良行为记录 Offences
+// 版本 6 增加了节点类别 Classes
+// 版本 7 增加了交互记录的多级评分 Ratings 与按车辆保存的轨迹 Trajectories
+const snapshotVersion = 7
+
+// snapshotMagic 二进制快照的文件头
+var snapshotMagic = []byte("RPSNAP")
+
+// snapshot 持久化的信誉管理器状态
+type snapshot struct {
+	Version      int           `json:"version"`
+	Config       config.Config `json:"config"`
+	Peers        []string      `json:"peers"`
+	Interactions []Interaction `json:"interactions"`
+	// 按首次出现顺序保存的各交互对聚合证据，仅在压缩过历史时写入
+	Compacted []FoldedEvidence `json:"compacted,omitempty"`
+	// 宽恕模型的不良行为记录，压缩后无法从保留的记录重放
+	Offences map[string]offenceRecord `json:"offences,omitempty"`
+	// 节点类别，决定各目标的基础率
+	Classes map[string]string `json:"classes,omitempty"`
+	// 按车辆保存的带时间戳轨迹
+	Trajectories trajectoryStore `json:"trajectories,omitempty"`
+}
+
+// Save 将交互记录、邻居 ID 与配置写入 w。
+// 时间戳只保留墙上时钟读数，恢复后的信誉值与保存前一致。
+func (rm *ReputationManager) Save(w io.Writer, format SnapshotFormat) error {
+	rm.mu.RLock()
+	snap := snapshot{
+		Version:      snapshotVersion,
+		Config:       rm.cfg,
+		Peers:        rm.peerIDsLocked(),
+		Interactions: rm.interactions,
+		Compacted:    rm.store.foldedEvidence(),
+		Offences:     rm.offenceRecords(),
+		Classes:      rm.nodeClassesLocked(),
+		Trajectories: rm.trajectories.clone(),
+	}
+	rm.mu.RUnlock()
+
+	switch format {
+	case SnapshotJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	case SnapshotBinary:
+		if _, err := w.Write(snapshotMagic); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint16(snapshotVersion)); err != nil {
+			return err
+		}
+		return gob.NewEncoder(w).Encode(snap)
+	default:
+		return fmt.Errorf("未知的快照格式: %d", format)
+	}
+}
+
+// Load 从 r 恢复状态，自动识别 JSON 与二进制格式，替换当前的配置与交互记录。
+// 快照中的配置未通过 ValidateConfig 时拒绝恢复。
+// 邻居只能恢复其 ID，需要调用 AddPeer 重新关联后才会参与推荐意见计算。
+func (rm *ReputationManager) Load(r io.Reader) error {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(snapshotMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var snap snapshot
+	if bytes.Equal(head, snapshotMagic) {
+		if _, err := br.Discard(len(snapshotMagic)); err != nil {
+			return err
+		}
+		var version uint16
+		if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+			return err
+		}
+		if version > snapshotVersion {
+			return fmt.Errorf("不支持的快照版本: %d", version)
+		}
+		if err := gob.NewDecoder(br).Decode(&snap); err != nil {
+			return err
+		}
+	} else {
+		if err := json.NewDecoder(br).Decode(&snap); err != nil {
+			return err
+		}
+		if snap.Version > snapshotVersion {
+			return fmt.Errorf("不支持的快照版本: %d", snap.Version)
+		}
+	}
+
+	// 快照中的配置会替换当前配置，须与启动时的配置一样通过检查
+	if err := ValidateConfig(snap.Config); err != nil {
+		return fmt.Errorf("快照中的配置无效: %w", err)
+	}
+	rm.restore(snap)
+	return nil
+}
+
+// restore 用快照替换当前状态并重建证据索引
+func (rm *ReputationManager) restore(snap snapshot) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.cfg = snap.Config
+	rm.interactions = nil
+	rm.store = newEvidenceStore(rm.cfg)
+	rm.model = newTrustModel(rm.cfg.TrustModel, rm)
+	rm.compactAt = 0
+	// 已压缩的证据只恢复到证据索引中，其他信任模型只能重放保留的记录
+	rm.firstSeen = make(map[string]time.Time)
+	rm.offences = make(map[string]*offenceRecord)
+	rm.classes = make(map[string]string, len(snap.Classes))
+	for id, class := range snap.Classes {
+		rm.classes[id] = class
+	}
+	rm.trajectories = snap.Trajectories.clone()
+	if rm.trajectories == nil {
+		rm.trajectories = make(trajectoryStore)
+	}
+	for _, f := range snap.Compacted {
+		rm.store.addFolded(f)
+		if f.Count > 0 {
+			rm.noteSeen(f.From, f.Until)
+			rm.noteSeen(f.To, f.Until)
+		}
+	}
+	for _, inter := range snap.Interactions {
+		rm.interactions = append(rm.interactions, inter)
+		rm.store.add(inter)
+		rm.noteSeen(inter.From, inter.Timestamp)
+		rm.noteSeen(inter.To, inter.Timestamp)
+		rm.noteOffence(inter)
+		if rm.model != TrustModel(rm) {
+			rm.model.Ingest(inter)
+		}
+	}
+	// 旧版本快照没有不良行为记录，只能从保留的记录重放
+	if snap.Offences != nil {
+		rm.offences = make(map[string]*offenceRecord, len(snap.Offences))
+		for id, rec := range snap.Offences {
+			rec := rec
+			rm.offences[id] = &rec
+		}
+	}
+
+	rm.peers = make(map[string]RecommendationSource)
+	for _, id := range snap.Peers {
+		rm.peers[id] = nil // 等待 AddPeer 重新关联
+	}
+}
+
+// SaveFile 保存到文件，扩展名为 .json 时使用 JSON 格式，否则使用二进制格式
+func (rm *ReputationManager) SaveFile(path string) error {
+	format := SnapshotBinary
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = SnapshotJSON
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rm.Save(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadFile 从文件恢复状态
+func (rm *ReputationManager) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return rm.Load(f)
+}
+
+// PeerIDs 返回已登记的邻居 ID（按字典序）
+func (rm *ReputationManager) PeerIDs() []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.peerIDsLocked()
+}
+
+func (rm *ReputationManager) peerIDsLocked() []string {
+	ids := make([]string, 0, len(rm.peers))
+	for id := range rm.peers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
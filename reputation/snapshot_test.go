@@ -0,0 +1,111 @@
+package reputation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// jsonString 以 JSON 比较含时间戳的结构（恢复后的时间只保留墙上时钟读数）
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// snapshotFixture 构造包含压缩证据、不良行为记录、节点类别与轨迹的管理器，peer 为其邻居
+func snapshotFixture(t0 time.Time) (rm, peer *ReputationManager) {
+	cfg := testConfig()
+	cfg.DecayMode = DecayExponential
+	cfg.HalfLifePos, cfg.HalfLifeNeg = 2000, 4000
+	cfg.RetentionMaxAge = 1500
+	cfg.RedemptionRate = 0.0005
+	cfg.NodeClassBaseRate = map[string]float64{NodeClassRSU: 0.9}
+	cfg.TrajectoryWindow = 600
+
+	rm, peer = NewReputationManager(cfg), NewReputationManager(cfg)
+	rm.AddPeer("peer", peer)
+	rm.SetNodeClass("rsu", NodeClassRSU)
+	for k := 0; k < 40; k++ {
+		ts := t0.Add(time.Duration(k*100) * time.Second)
+		for j, target := range []string{"good", "bad", "rsu", "peer"} {
+			inter := Interaction{From: "me", To: target, PosEvents: 3 - j%3, NegEvents: j % 2, Timestamp: ts, CommQuality: 0.8,
+				TrajUser: line(0, 0.1), TrajProvider: line(0, 0.1)}
+			if target == "bad" && k%10 == 9 {
+				inter.Events = []Event{{Type: EventForgedData, Count: 2}}
+			}
+			rm.AddInteraction(inter)
+		}
+		peer.AddInteraction(Interaction{From: "peer", To: "good", PosEvents: 2, Timestamp: ts, CommQuality: 0.9})
+		rm.RecordTrajectory("me", ts, Vector{Location: float64(k) / 40, Speed: 10})
+		rm.RecordTrajectory("peer", ts, Vector{Location: float64(k) / 40, Speed: 11})
+	}
+	rm.Compact(t0.Add(4000 * time.Second))
+	return rm, peer
+}
+
+// TestSnapshotRoundTrip JSON 与二进制快照恢复后，信誉、解释与历史统计与保存前一致
+func TestSnapshotRoundTrip(t *testing.T) {
+	t0 := time.Unix(1_000_000, 0)
+	rm, peer := snapshotFixture(t0)
+	if st := rm.HistoryStats(); st.FoldedRecords == 0 {
+		t.Fatal("fixture did not compact any records")
+	}
+
+	for _, format := range []SnapshotFormat{SnapshotJSON, SnapshotBinary} {
+		var buf bytes.Buffer
+		if err := rm.Save(&buf, format); err != nil {
+			t.Fatalf("format %d: save: %v", format, err)
+		}
+		restored := NewReputationManager(testConfig())
+		if err := restored.Load(&buf); err != nil {
+			t.Fatalf("format %d: load: %v", format, err)
+		}
+		restored.AddPeer("peer", peer)
+
+		if got, want := restored.HistoryStats(), rm.HistoryStats(); got != want {
+			t.Errorf("format %d: history stats %+v, want %+v", format, got, want)
+		}
+		for _, offset := range []time.Duration{3900 * time.Second, 4000 * time.Second, 9000 * time.Second} {
+			now := t0.Add(offset)
+			for _, target := range []string{"good", "bad", "rsu", "peer", "unknown"} {
+				want := rm.ComputeReputation("me", target, []string{"peer"}, now)
+				if got := restored.ComputeReputation("me", target, []string{"peer"}, now); got != want {
+					t.Errorf("format %d now=t0+%v target=%s: reputation %v, want %v", format, offset, target, got, want)
+				}
+				want2 := jsonString(rm.ExplainReputation("me", target, []string{"peer"}, now))
+				if got := jsonString(restored.ExplainReputation("me", target, []string{"peer"}, now)); got != want2 {
+					t.Errorf("format %d now=t0+%v target=%s: explanation differs\n got %s\nwant %s", format, offset, target, got, want2)
+				}
+			}
+		}
+		if got, want := jsonString(restored.forgivenessStatus("bad", t0.Add(5000*time.Second))), jsonString(rm.forgivenessStatus("bad", t0.Add(5000*time.Second))); got != want {
+			t.Errorf("format %d: forgiveness %+v, want %+v", format, got, want)
+		}
+		if restored.NodeClass("rsu") != NodeClassRSU {
+			t.Errorf("format %d: node class %q", format, restored.NodeClass("rsu"))
+		}
+		if got, want := jsonString(restored.TrajectoryWindow("peer", t0, t0.Add(time.Hour))), jsonString(rm.TrajectoryWindow("peer", t0, t0.Add(time.Hour))); got != want {
+			t.Errorf("format %d: trajectory %v, want %v", format, got, want)
+		}
+	}
+}
+
+// TestLoadRejectsInvalidConfig 快照中的配置未通过检查时拒绝恢复，原有状态不变
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.TrustModel = ModelBeta
+	cfg.PeerTransport = TransportHTTP
+	var buf bytes.Buffer
+	if err := NewReputationManager(cfg).Save(&buf, SnapshotJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	rm := NewReputationManager(testConfig())
+	if err := rm.Load(&buf); err == nil {
+		t.Fatal("loaded a snapshot with beta model over http transport")
+	}
+	if rm.Config().TrustModel != "" {
+		t.Errorf("config replaced by rejected snapshot: %q", rm.Config().TrustModel)
+	}
+}
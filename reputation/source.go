@@ -0,0 +1,186 @@
+package reputation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RecommendationSource 推荐意见来源：返回某个邻居对目标的意见及轨迹摘要。
+// *ReputationManager 是进程内实现，HTTPSource 通过 HTTP 查询其他进程中的节点。
+type RecommendationSource interface {
+	Recommend(q RecommendationQuery) (Recommendation, error)
+}
+
+// 邻居推荐的传输方式
+const (
+	TransportMemory = "memory" // 进程内直接调用 *ReputationManager
+	TransportHTTP   = "http"   // 经本机回环 HTTP 查询
+)
+
+// RecommendationQuery 推荐查询
+type RecommendationQuery struct {
+	Recommender string    `json:"recommender"`       // 被询问的邻居 ID
+	Target      string    `json:"target"`            // 目标节点 ID
+	Context     string    `json:"context,omitempty"` // 数据类别，空值表示不区分
+	Hops        int       `json:"hops"`              // 推荐链最多还能经过的推荐者数量，<=1 时只返回直接证据
+	Visited     []string  `json:"visited"`           // 当前路径上已访问的节点，用于环路检测
+	Now         time.Time `json:"now"`
+}
+
+// Recommendation 邻居对目标的推荐意见
+type Recommendation struct {
+	Opinion              Opinion           `json:"opinion"`
+	Interactions         int               `json:"interactions"` // 推荐者与目标的直接交互次数
+	InteractionFreq      float64           `json:"if"`
+	TrajectorySimilarity float64           `json:"sim"`
+	Weight               float64           `json:"weight"` // δ = ρ₁×IF + ρ₂×SIM
+	Trajectory           TrajectorySummary `json:"trajectory"`
+	Paths                []TrustPath       `json:"paths,omitempty"`
+}
+
+// Found 推荐者（直接或经传递）是否有关于目标的证据
+func (r Recommendation) Found() bool {
+	return r.Interactions > 0 || len(r.Paths) > 0
+}
+
+// TrajectorySummary 推荐者与目标轨迹的摘要
+type TrajectorySummary struct {
+	RecommenderPoints int     `json:"recommender_points"`
+	TargetPoints      int     `json:"target_points"`
+	RecommenderSpeed  float64 `json:"recommender_speed"` // 平均速度
+	TargetSpeed       float64 `json:"target_speed"`
+}
+
+// summarizeTrajectories 生成轨迹摘要
+func summarizeTrajectories(recommender, target []Vector) TrajectorySummary {
+	return TrajectorySummary{
+		RecommenderPoints: len(recommender),
+		TargetPoints:      len(target),
+		RecommenderSpeed:  meanSpeed(recommender),
+		TargetSpeed:       meanSpeed(target),
+	}
+}
+
+func meanSpeed(traj []Vector) float64 {
+	if len(traj) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range traj {
+		sum += v.Speed
+	}
+	return sum / float64(len(traj))
+}
+
+// ============ HTTP 实现 ============
+
+// recommendPath HTTP 推荐接口路径
+const recommendPath = "/recommend"
+
+// NewRecommendationHandler 返回以 JSON 形式提供 src 推荐意见的 HTTP 处理器（POST /recommend）
+func NewRecommendationHandler(src RecommendationSource) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(recommendPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var q RecommendationQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec, err := src.Recommend(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rec)
+	})
+	return mux
+}
+
+// HTTPSource 通过 HTTP 查询远端节点的推荐意见
+type HTTPSource struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewHTTPSource 创建 HTTP 推荐来源，baseURL 形如 http://127.0.0.1:8080
+func NewHTTPSource(baseURL string) *HTTPSource {
+	return &HTTPSource{BaseURL: baseURL, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Recommend 实现 RecommendationSource
+func (s *HTTPSource) Recommend(q RecommendationQuery) (Recommendation, error) {
+	body, err := json.Marshal(q)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	resp, err := s.Client.Post(s.BaseURL+recommendPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Recommendation{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Recommendation{}, fmt.Errorf("推荐查询失败: %s", resp.Status)
+	}
+
+	var rec Recommendation
+	if err := json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+		return Recommendation{}, err
+	}
+	return rec, nil
+}
+
+// LoopbackServer 在本机回环地址上提供推荐接口的 HTTP 服务
+type LoopbackServer struct {
+	URL    string
+	server *http.Server
+	done   chan error // 服务退出时的错误，正常关闭时为 nil
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// StartLoopbackServer 在 127.0.0.1 的随机端口上启动推荐服务
+func StartLoopbackServer(src RecommendationSource) (*LoopbackServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &LoopbackServer{
+		URL:    "http://" + ln.Addr().String(),
+		server: &http.Server{Handler: NewRecommendationHandler(src)},
+		done:   make(chan error, 1),
+	}
+	go func() {
+		err := s.server.Serve(ln)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		s.done <- err
+	}()
+	return s, nil
+}
+
+// Close 关闭服务，服务曾异常退出时返回该错误。可以重复调用，之后的调用返回相同的结果
+func (s *LoopbackServer) Close() error {
+	s.closeOnce.Do(func() {
+		if err := s.server.Close(); err != nil {
+			s.closeErr = err
+			return
+		}
+		if err := <-s.done; err != nil {
+			s.closeErr = fmt.Errorf("推荐服务异常退出: %w", err)
+		}
+	})
+	return s.closeErr
+}
@@ -0,0 +1,42 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoopbackServer 经回环 HTTP 得到的推荐与进程内调用一致，重复关闭服务不会阻塞
+func TestLoopbackServer(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	rm := NewReputationManager(testConfig())
+	rm.AddInteraction(Interaction{From: "peer", To: "target", PosEvents: 3, NegEvents: 1, Timestamp: now, CommQuality: 0.9})
+
+	srv, err := StartLoopbackServer(rm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := RecommendationQuery{Recommender: "peer", Target: "target", Hops: 1, Now: now}
+	want, _ := rm.Recommend(q)
+	got, err := NewHTTPSource(srv.URL).Recommend(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(got.Opinion.Belief, want.Opinion.Belief) || !closeTo(got.Weight, want.Weight) {
+		t.Errorf("http recommendation %+v, in-process %+v", got, want)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for k := 0; k < 3; k++ {
+			if err := srv.Close(); err != nil {
+				t.Errorf("close %d: %v", k, err)
+			}
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("repeated Close blocked")
+	}
+}
@@ -0,0 +1,249 @@
+package reputation
+
+import (
+	"block/config"
+	"math"
+	"sort"
+	"time"
+)
+
+// pairKey 交互对 (From, To) 在某个上下文中的证据
+type pairKey struct {
+	From    string
+	To      string
+	Context string
+}
+
+// pairEvidence 单个交互对 (From, To) 的增量证据累加器。
+// times 按时间升序保存，cumPos/cumNeg 是对应的前缀和（首元素为 0），
+// bucket 模式下只需二分查找 TRecent 的切分点即可得到近期/过去证据；
+// exponential 模式下 decayPos/decayNeg 保存以 refTime 为基准的衰减累加值。
+type pairEvidence struct {
+	key    pairKey
+	times  []time.Time
+	cumPos []float64
+	cumNeg []float64
+
+	refTime  time.Time
+	decayPos float64
+	decayNeg float64
+
+	// 通信质量之和与记录数（含已压缩的记录）
+	commSum   float64
+	commCount int
+
+	// 该交互对的第一条轨迹（用于推荐意见的轨迹相似度）
+	trajUser     []Vector
+	trajProvider []Vector
+
+	// 已压缩的过期记录，其证据保存在 cumPos/cumNeg 的首元素与衰减累加值中
+	folded FoldedEvidence
+}
+
+// count 返回交互对的记录总数（含已压缩的记录）
+func (pe *pairEvidence) count() int {
+	return pe.folded.Count + len(pe.times)
+}
+
+// FoldedEvidence 交互对中被压缩的过期记录的聚合证据
+type FoldedEvidence struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Context  string    `json:"context,omitempty"`
+	Count    int       `json:"count"`     // 被压缩的记录数
+	Pos      float64   `json:"pos"`       // 正面事件总数
+	Neg      float64   `json:"neg"`       // 负面事件总数
+	DecayPos float64   `json:"decay_pos"` // 以 Until 为基准的正面事件衰减累加值
+	DecayNeg float64   `json:"decay_neg"` // 以 Until 为基准的负面事件衰减累加值
+	Until    time.Time `json:"until"`     // 被压缩记录中最新的时间戳
+	CommSum  float64   `json:"comm_sum"`  // 通信质量之和
+
+	// 交互对的第一条轨迹，压缩后仍保留
+	TrajUser     []Vector `json:"traj_user,omitempty"`
+	TrajProvider []Vector `json:"traj_provider,omitempty"`
+}
+
+// evidenceStore 以 (From, To) 为索引的证据存储，
+// 在 AddInteraction 时增量更新，查询代价与邻居数量相关而与历史长度无关
+type evidenceStore struct {
+	lambdaPos float64
+	lambdaNeg float64
+	severity  map[string]float64 // 事件类型的严重程度
+	utility   []float64          // 各评价等级的效用
+
+	// keys/byFrom/byTo 按首次出现的顺序保存，保证累加顺序确定
+	keys   []pairKey
+	pairs  map[pairKey]*pairEvidence
+	byFrom map[string][]*pairEvidence // From -> 各 To 的证据
+	byTo   map[string][]*pairEvidence // To -> 各 From 的证据
+}
+
+func newEvidenceStore(cfg config.Config) *evidenceStore {
+	_, utility := ratingScaleOf(cfg)
+	return &evidenceStore{
+		lambdaPos: decayRate(cfg.LambdaPos, cfg.HalfLifePos),
+		lambdaNeg: decayRate(cfg.LambdaNeg, cfg.HalfLifeNeg),
+		severity:  cfg.EventSeverity,
+		utility:   utility,
+		pairs:     make(map[pairKey]*pairEvidence),
+		byFrom:    make(map[string][]*pairEvidence),
+		byTo:      make(map[string][]*pairEvidence),
+	}
+}
+
+// add 将一次交互累加到索引中
+func (s *evidenceStore) add(inter Interaction) {
+	key := pairKey{From: inter.From, To: inter.To, Context: inter.Context}
+	pe, ok := s.pairs[key]
+	if !ok {
+		pe = &pairEvidence{
+			key:          key,
+			cumPos:       []float64{0},
+			cumNeg:       []float64{0},
+			refTime:      inter.Timestamp,
+			trajUser:     inter.TrajUser,
+			trajProvider: inter.TrajProvider,
+			folded: FoldedEvidence{
+				From:         inter.From,
+				To:           inter.To,
+				Context:      inter.Context,
+				TrajUser:     inter.TrajUser,
+				TrajProvider: inter.TrajProvider,
+			},
+		}
+		s.register(key, pe)
+	}
+	pos, neg := eventEvidence(s.severity, s.utility, inter)
+	pe.insert(inter.Timestamp, pos, neg, s.lambdaPos, s.lambdaNeg)
+	pe.commSum += inter.CommQuality
+	pe.commCount++
+}
+
+// addFolded 以压缩后的聚合证据创建交互对，须在该交互对的任何记录之前调用
+func (s *evidenceStore) addFolded(f FoldedEvidence) {
+	key := pairKey{From: f.From, To: f.To, Context: f.Context}
+	pe := &pairEvidence{
+		key:          key,
+		cumPos:       []float64{f.Pos},
+		cumNeg:       []float64{f.Neg},
+		refTime:      f.Until,
+		decayPos:     f.DecayPos,
+		decayNeg:     f.DecayNeg,
+		trajUser:     f.TrajUser,
+		trajProvider: f.TrajProvider,
+		commSum:      f.CommSum,
+		commCount:    f.Count,
+		folded:       f,
+	}
+	s.register(key, pe)
+}
+
+// foldedEvidence 按首次出现顺序返回所有交互对的聚合证据，没有压缩过任何记录时返回 nil
+func (s *evidenceStore) foldedEvidence() []FoldedEvidence {
+	var compacted bool
+	for _, pe := range s.pairs {
+		if pe.folded.Count > 0 {
+			compacted = true
+			break
+		}
+	}
+	if !compacted {
+		return nil
+	}
+
+	out := make([]FoldedEvidence, 0, len(s.keys))
+	for _, key := range s.keys {
+		out = append(out, s.pairs[key].folded)
+	}
+	return out
+}
+
+// firstPair 返回 from→to 最早出现的交互对证据（不区分上下文），不存在时返回 nil
+func (s *evidenceStore) firstPair(from, to string) *pairEvidence {
+	for _, pe := range s.byFrom[from] {
+		if pe.key.To == to {
+			return pe
+		}
+	}
+	return nil
+}
+
+// register 登记新的交互对
+func (s *evidenceStore) register(key pairKey, pe *pairEvidence) {
+	s.keys = append(s.keys, key)
+	s.pairs[key] = pe
+	s.byFrom[key.From] = append(s.byFrom[key.From], pe)
+	s.byTo[key.To] = append(s.byTo[key.To], pe)
+}
+
+// insert 按时间顺序插入一条证据，并更新前缀和与衰减累加值
+func (pe *pairEvidence) insert(ts time.Time, pos, neg, lambdaPos, lambdaNeg float64) {
+	n := len(pe.times)
+	if n == 0 || !ts.Before(pe.times[n-1]) {
+		pe.times = append(pe.times, ts)
+		pe.cumPos = append(pe.cumPos, pe.cumPos[n]+pos)
+		pe.cumNeg = append(pe.cumNeg, pe.cumNeg[n]+neg)
+	} else {
+		// 乱序到达（并发写入时可能出现）：插入到正确位置，并重建其后的前缀和
+		idx := sort.Search(n, func(i int) bool { return pe.times[i].After(ts) })
+		pe.times = append(pe.times, time.Time{})
+		copy(pe.times[idx+1:], pe.times[idx:])
+		pe.times[idx] = ts
+
+		pe.cumPos = append(pe.cumPos, 0)
+		pe.cumNeg = append(pe.cumNeg, 0)
+		for i := n + 1; i > idx+1; i-- {
+			pe.cumPos[i] = pe.cumPos[i-1] + pos
+			pe.cumNeg[i] = pe.cumNeg[i-1] + neg
+		}
+		pe.cumPos[idx+1] = pe.cumPos[idx] + pos
+		pe.cumNeg[idx+1] = pe.cumNeg[idx] + neg
+	}
+
+	// 衰减累加值始终以最新时间为基准
+	if ts.After(pe.refTime) {
+		dt := ts.Sub(pe.refTime).Seconds()
+		pe.decayPos *= math.Exp(-lambdaPos * dt)
+		pe.decayNeg *= math.Exp(-lambdaNeg * dt)
+		pe.refTime = ts
+	}
+	dt := pe.refTime.Sub(ts).Seconds()
+	pe.decayPos += pos * math.Exp(-lambdaPos*dt)
+	pe.decayNeg += neg * math.Exp(-lambdaNeg*dt)
+}
+
+// splitRecent 返回 bucket 模式下近期与过去的正、负事件数，已压缩的记录计入过去
+func (pe *pairEvidence) splitRecent(now time.Time, tRecent float64) (recentPos, recentNeg, pastPos, pastNeg float64) {
+	n := len(pe.times)
+	// times 升序，距今时间单调递减，第一个满足 Δt <= TRecent 的位置即为切分点
+	split := sort.Search(n, func(i int) bool { return now.Sub(pe.times[i]).Seconds() <= tRecent })
+	recentPos = pe.cumPos[n] - pe.cumPos[split]
+	recentNeg = pe.cumNeg[n] - pe.cumNeg[split]
+	return recentPos, recentNeg, pe.cumPos[split], pe.cumNeg[split]
+}
+
+// decayed 返回 exponential 模式下衰减到 now 时刻的正、负事件累加值。
+// 与逐条扫描一致，晚于 now 的记录按 Δt = 0 计（不衰减也不放大）：
+// now 不早于最新记录时直接由累加值换算，否则逐条累加
+func (pe *pairEvidence) decayed(now time.Time, lambdaPos, lambdaNeg float64) (pos, neg float64) {
+	if dt := now.Sub(pe.refTime).Seconds(); dt >= 0 {
+		return pe.decayPos * math.Exp(-lambdaPos*dt), pe.decayNeg * math.Exp(-lambdaNeg*dt)
+	}
+
+	pos, neg = pe.foldedDecayed(now, lambdaPos, lambdaNeg)
+	for k, ts := range pe.times {
+		dt := math.Max(now.Sub(ts).Seconds(), 0)
+		pos += (pe.cumPos[k+1] - pe.cumPos[k]) * math.Exp(-lambdaPos*dt)
+		neg += (pe.cumNeg[k+1] - pe.cumNeg[k]) * math.Exp(-lambdaNeg*dt)
+	}
+	return pos, neg
+}
+
+// foldedDecayed 返回已压缩记录衰减到 now 时刻的正、负事件累加值
+func (pe *pairEvidence) foldedDecayed(now time.Time, lambdaPos, lambdaNeg float64) (pos, neg float64) {
+	dt := now.Sub(pe.folded.Until).Seconds()
+	if dt < 0 {
+		dt = 0
+	}
+	return pe.folded.DecayPos * math.Exp(-lambdaPos*dt), pe.folded.DecayNeg * math.Exp(-lambdaNeg*dt)
+}
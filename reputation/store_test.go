@@ -0,0 +1,136 @@
+package reputation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// scanAlphaBeta 逐条扫描交互记录计算 target 的 α、β（建立索引之前的算法），作为索引结果的参照
+func scanAlphaBeta(rm *ReputationManager, target string, now time.Time) (alpha, beta float64) {
+	for _, inter := range rm.GetInteractions() {
+		if inter.To != target {
+			continue
+		}
+		wPos, wNeg := rm.evidenceWeights(now.Sub(inter.Timestamp).Seconds())
+		pos, neg := rm.interactionEvidence(inter)
+		alpha += wPos * pos
+		beta += wNeg * neg
+	}
+	return alpha, beta
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-9*math.Max(1, math.Abs(want))
+}
+
+// TestIndexMatchesScan 索引得到的 α、β 与逐条扫描一致，包括乱序写入与早于最新记录的查询时间
+func TestIndexMatchesScan(t *testing.T) {
+	t0 := time.Unix(1_000_000, 0)
+	for _, mode := range []string{DecayBucket, DecayExponential} {
+		cfg := testConfig()
+		cfg.DecayMode = mode
+		cfg.HalfLifePos, cfg.HalfLifeNeg = 1000, 2000
+		cfg.EventSeverity = map[string]float64{EventForgedData: 5, EventDelayedData: 0.3}
+		rm := NewReputationManager(cfg)
+
+		rng := rand.New(rand.NewSource(1))
+		targets := []string{"a", "b", "c"}
+		for k := 0; k < 300; k++ {
+			inter := Interaction{
+				From:      []string{"x", "y"}[rng.Intn(2)],
+				To:        targets[rng.Intn(len(targets))],
+				PosEvents: rng.Intn(3),
+				NegEvents: rng.Intn(2),
+				Timestamp: t0.Add(time.Duration(rng.Intn(6000)) * time.Second),
+			}
+			if rng.Intn(4) == 0 {
+				inter.Events = []Event{{Type: EventDelayedData, Count: 1}, {Type: EventForgedData, Count: rng.Intn(2)}}
+			}
+			rm.AddInteraction(inter)
+		}
+
+		for _, offset := range []time.Duration{0, 500 * time.Second, 3000 * time.Second, 6000 * time.Second, 9000 * time.Second} {
+			now := t0.Add(offset)
+			for _, target := range targets {
+				wantA, wantB := scanAlphaBeta(rm, target, now)
+				gotA, gotB := rm.directEvidence(target, ContextAny, now)
+				if !closeTo(gotA, wantA) || !closeTo(gotB, wantB) {
+					t.Errorf("%s now=t0+%v target=%s: index (%v, %v), scan (%v, %v)", mode, offset, target, gotA, gotB, wantA, wantB)
+				}
+
+				eb := rm.evidenceBreakdown(target, ContextAny, now)
+				if !closeTo(eb.Alpha, eb.AlphaRecent+eb.AlphaPast) || !closeTo(eb.Beta, eb.BetaRecent+eb.BetaPast) {
+					t.Errorf("%s now=t0+%v target=%s: breakdown α=%v≠%v+%v or β=%v≠%v+%v", mode, offset, target,
+						eb.Alpha, eb.AlphaRecent, eb.AlphaPast, eb.Beta, eb.BetaRecent, eb.BetaPast)
+				}
+			}
+		}
+	}
+}
+
+// TestDecayedBeforeLatestRecord 查询时间早于最新记录时，每条记录的 Δt 分别截断为 0
+func TestDecayedBeforeLatestRecord(t *testing.T) {
+	cfg := testConfig()
+	cfg.DecayMode = DecayExponential
+	cfg.HalfLifePos = 1000
+	rm := NewReputationManager(cfg)
+
+	t0 := time.Unix(1_000_000, 0)
+	rm.AddInteraction(Interaction{From: "x", To: "a", PosEvents: 10, Timestamp: t0})
+	rm.AddInteraction(Interaction{From: "x", To: "a", PosEvents: 1, Timestamp: t0.Add(5000 * time.Second)})
+
+	alpha, _ := rm.directEvidence("a", ContextAny, t0)
+	if !closeTo(alpha, 11) {
+		t.Errorf("α at t0 = %v, want 11", alpha)
+	}
+}
+
+// TestCommQualityByContext 通信质量与证据相同按上下文加权
+func TestCommQualityByContext(t *testing.T) {
+	cfg := testConfig()
+	cfg.ContextSimilarity = 0.5
+	rm := NewReputationManager(cfg)
+
+	now := time.Unix(1_000_000, 0)
+	rm.AddInteraction(Interaction{From: "x", To: "a", Context: "map", PosEvents: 1, Timestamp: now, CommQuality: 0.9})
+	rm.AddInteraction(Interaction{From: "x", To: "a", Context: "video", PosEvents: 1, Timestamp: now, CommQuality: 0.3})
+
+	tests := []struct {
+		context string
+		want    float64
+	}{
+		{ContextAny, 0.6},
+		{"map", (0.9 + 0.5*0.3) / 1.5},
+		{"video", (0.3 + 0.5*0.9) / 1.5},
+	}
+	for _, tt := range tests {
+		if got := rm.evidenceBreakdown("a", tt.context, now).CommQuality; !closeTo(got, tt.want) {
+			t.Errorf("context %q: comm quality = %v, want %v", tt.context, got, tt.want)
+		}
+	}
+}
+
+// TestRatingsEvidence 评价向量按效用折算为 α、β，无效的评价向量被拒绝
+func TestRatingsEvidence(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	rm := NewReputationManager(testConfig())
+
+	// 默认效用 1/0.7/0.3/0
+	rm.AddInteraction(Interaction{From: "x", To: "a", Ratings: []float64{2, 1, 1, 1}, Timestamp: now})
+	rm.AddInteraction(Interaction{From: "x", To: "a", Ratings: []float64{1, 1, 1, 1, 1}, Timestamp: now})
+	rm.AddInteraction(Interaction{From: "x", To: "a", Ratings: []float64{3, -1}, Timestamp: now})
+
+	if n := len(rm.GetInteractions()); n != 1 {
+		t.Fatalf("%d interactions recorded, want 1", n)
+	}
+	alpha, beta := rm.directEvidence("a", ContextAny, now)
+	wPos, wNeg := rm.evidenceWeights(0)
+	if want := wPos * (2 + 0.7 + 0.3); !closeTo(alpha, want) {
+		t.Errorf("α = %v, want %v", alpha, want)
+	}
+	if want := wNeg * (0.3 + 0.7 + 1); !closeTo(beta, want) {
+		t.Errorf("β = %v, want %v", beta, want)
+	}
+}
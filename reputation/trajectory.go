@@ -0,0 +1,213 @@
+package reputation
+
+import (
+	"math"
+	"sort"
+)
+
+// TrajectoryMetric 轨迹位置距离接口，用于公式6中的位置差异项
+type TrajectoryMetric interface {
+	// Name 返回度量名称
+	Name() string
+	// Distance 返回两条轨迹的位置距离，归一化到 [0,1]，0 表示完全重合
+	Distance(traj1, traj2 []Vector) float64
+}
+
+// 轨迹距离度量名称，通过 config.Config.TrajectoryMetric 选择
+const (
+	MetricLCS       = "lcs"       // 最长公共子序列（论文公式8，默认）
+	MetricDTW       = "dtw"       // 动态时间规整
+	MetricFrechet   = "frechet"   // 离散 Fréchet 距离
+	MetricEDR       = "edr"       // 实数序列编辑距离
+	MetricHausdorff = "hausdorff" // Hausdorff 距离
+)
+
+// trajectoryMetrics 度量名称到构造函数的映射，owner 用于读取位置阈值
+var trajectoryMetrics = map[string]func(owner *ReputationManager) TrajectoryMetric{
+	MetricLCS:       func(owner *ReputationManager) TrajectoryMetric { return lcsMetric{owner} },
+	MetricDTW:       func(owner *ReputationManager) TrajectoryMetric { return dtwMetric{owner.pointDistance} },
+	MetricFrechet:   func(owner *ReputationManager) TrajectoryMetric { return frechetMetric{owner.pointDistance} },
+	MetricEDR:       func(owner *ReputationManager) TrajectoryMetric { return edrMetric{owner.pointDistance} },
+	MetricHausdorff: func(owner *ReputationManager) TrajectoryMetric { return hausdorffMetric{owner.pointDistance} },
+}
+
+// TrajectoryMetricNames 返回所有可用的轨迹距离度量名称
+func TrajectoryMetricNames() []string {
+	names := make([]string, 0, len(trajectoryMetrics))
+	for name := range trajectoryMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// trajectoryMetric 返回配置的轨迹距离度量，空名称或未知名称使用 LCS
+func (rm *ReputationManager) trajectoryMetric() TrajectoryMetric {
+	if ctor, ok := trajectoryMetrics[rm.cfg.TrajectoryMetric]; ok {
+		return ctor(rm)
+	}
+	return lcsMetric{rm}
+}
+
+// pointDistance 返回两个轨迹点的位置距离，以 LocationThreshold、LateralThreshold 为单位，
+// 距离小于 1 与 colocated 判定为同一位置等价。已知车道且车道不同时距离至少为 1
+func (rm *ReputationManager) pointDistance(p, q Vector) float64 {
+	threshold := rm.cfg.LocationThreshold
+	if threshold <= 0 {
+		threshold = 0.05 // 位置相似阈值
+	}
+	d := math.Abs(p.Location-q.Location) / threshold
+	if rm.cfg.LateralThreshold > 0 {
+		d = math.Hypot(d, (p.Lateral-q.Lateral)/rm.cfg.LateralThreshold)
+	}
+	if p.Lane != 0 && q.Lane != 0 && p.Lane != q.Lane {
+		d = math.Max(d, 1)
+	}
+	return d
+}
+
+// normalizeDistance 将以阈值为单位的距离 D ∈ [0,∞) 映射到 [0,1)：D/(1+D)，
+// 恰好相距一个阈值时为 0.5
+func normalizeDistance(d float64) float64 {
+	return d / (1 + d)
+}
+
+// ============ LCS：论文公式8 ============
+
+type lcsMetric struct{ owner *ReputationManager }
+
+func (lcsMetric) Name() string { return MetricLCS }
+
+// Distance 未匹配点的比例 (max(m,n) - LCS) / max(m,n)
+func (m lcsMetric) Distance(traj1, traj2 []Vector) float64 {
+	maxLen := math.Max(float64(len(traj1)), float64(len(traj2)))
+	if maxLen == 0 {
+		return 0
+	}
+	return (maxLen - float64(m.owner.computeLCS(traj1, traj2))) / maxLen
+}
+
+// ============ DTW：动态时间规整 ============
+
+type dtwMetric struct{ ground func(p, q Vector) float64 }
+
+func (dtwMetric) Name() string { return MetricDTW }
+
+// Distance 最优规整路径上的累计距离除以 max(m,n)，即每个点的平均距离，再归一化
+func (m dtwMetric) Distance(traj1, traj2 []Vector) float64 {
+	if len(traj1) == 0 || len(traj2) == 0 {
+		return emptyTrajectoryDistance(traj1, traj2)
+	}
+	cost := couplingTable(traj1, traj2, m.ground, func(d, prev float64) float64 { return d + prev })
+	n := math.Max(float64(len(traj1)), float64(len(traj2)))
+	return normalizeDistance(cost / n)
+}
+
+// ============ 离散 Fréchet 距离 ============
+
+type frechetMetric struct{ ground func(p, q Vector) float64 }
+
+func (frechetMetric) Name() string { return MetricFrechet }
+
+// Distance 所有单调耦合中最大点距的最小值，再归一化
+func (m frechetMetric) Distance(traj1, traj2 []Vector) float64 {
+	if len(traj1) == 0 || len(traj2) == 0 {
+		return emptyTrajectoryDistance(traj1, traj2)
+	}
+	return normalizeDistance(couplingTable(traj1, traj2, m.ground, math.Max))
+}
+
+// couplingTable 在单调耦合上做动态规划：
+// c[i][j] = combine(d(i,j), min(c[i-1][j], c[i][j-1], c[i-1][j-1]))，返回 c[m-1][n-1]。
+// combine 为求和时即 DTW，为取最大值时即离散 Fréchet 距离
+func couplingTable(traj1, traj2 []Vector, ground func(p, q Vector) float64, combine func(d, prev float64) float64) float64 {
+	m, n := len(traj1), len(traj2)
+	prev := make([]float64, n)
+	cur := make([]float64, n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			d := ground(traj1[i], traj2[j])
+			switch {
+			case i == 0 && j == 0:
+				cur[j] = d
+			case i == 0:
+				cur[j] = combine(d, cur[j-1])
+			case j == 0:
+				cur[j] = combine(d, prev[j])
+			default:
+				cur[j] = combine(d, math.Min(prev[j-1], math.Min(prev[j], cur[j-1])))
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[n-1]
+}
+
+// ============ EDR：实数序列编辑距离 ============
+
+type edrMetric struct{ ground func(p, q Vector) float64 }
+
+func (edrMetric) Name() string { return MetricEDR }
+
+// Distance 距离小于一个阈值的点视为匹配（代价 0），否则替换、插入、删除的代价均为 1，
+// 编辑距离除以 max(m,n)
+func (m edrMetric) Distance(traj1, traj2 []Vector) float64 {
+	if len(traj1) == 0 || len(traj2) == 0 {
+		return emptyTrajectoryDistance(traj1, traj2)
+	}
+
+	rows, cols := len(traj1), len(traj2)
+	prev := make([]int, cols+1)
+	cur := make([]int, cols+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= rows; i++ {
+		cur[0] = i
+		for j := 1; j <= cols; j++ {
+			subst := 1
+			if m.ground(traj1[i-1], traj2[j-1]) < 1 {
+				subst = 0
+			}
+			cur[j] = min(prev[j-1]+subst, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return float64(prev[cols]) / float64(max(rows, cols))
+}
+
+// ============ Hausdorff 距离 ============
+
+type hausdorffMetric struct{ ground func(p, q Vector) float64 }
+
+func (hausdorffMetric) Name() string { return MetricHausdorff }
+
+// Distance 两个方向上“到另一条轨迹最近点的距离”的最大值，再归一化。
+// 不考虑点的先后顺序
+func (m hausdorffMetric) Distance(traj1, traj2 []Vector) float64 {
+	if len(traj1) == 0 || len(traj2) == 0 {
+		return emptyTrajectoryDistance(traj1, traj2)
+	}
+	return normalizeDistance(math.Max(m.directed(traj1, traj2), m.directed(traj2, traj1)))
+}
+
+// directed 返回有向 Hausdorff 距离 max_{p∈a} min_{q∈b} d(p,q)
+func (m hausdorffMetric) directed(a, b []Vector) float64 {
+	var h float64
+	for _, p := range a {
+		nearest := math.Inf(1)
+		for _, q := range b {
+			nearest = math.Min(nearest, m.ground(p, q))
+		}
+		h = math.Max(h, nearest)
+	}
+	return h
+}
+
+// emptyTrajectoryDistance 有空轨迹时的距离：都为空时为 0，否则为 1
+func emptyTrajectoryDistance(traj1, traj2 []Vector) float64 {
+	if len(traj1) == 0 && len(traj2) == 0 {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,72 @@
+package reputation
+
+import "testing"
+
+// line 返回纵向位置依次为 locs 的轨迹
+func line(locs ...float64) []Vector {
+	traj := make([]Vector, len(locs))
+	for k, l := range locs {
+		traj[k] = Vector{Location: l, Speed: 10}
+	}
+	return traj
+}
+
+// TestTrajectoryMetrics 各度量在典型轨迹对上的距离，位置以阈值为单位，相邻点相距 10 个阈值
+func TestTrajectoryMetrics(t *testing.T) {
+	base := line(0, 10, 20)
+	cases := []struct {
+		name         string
+		traj1, traj2 []Vector
+		want         map[string]float64
+	}{
+		{"identical", base, line(0, 10, 20), map[string]float64{
+			MetricDTW: 0, MetricFrechet: 0, MetricHausdorff: 0, MetricEDR: 0, MetricLCS: 0,
+		}},
+		// 每个点都恰好相距一个阈值：连续度量为 1/(1+1)，匹配类度量判定为不同位置
+		{"shifted by one threshold", base, line(1, 11, 21), map[string]float64{
+			MetricDTW: 0.5, MetricFrechet: 0.5, MetricHausdorff: 0.5, MetricEDR: 1, MetricLCS: 1,
+		}},
+		// 只有最后一个点偏离 5 个阈值
+		{"one point off", base, line(0, 10, 25), map[string]float64{
+			MetricDTW: (5.0 / 3) / (1 + 5.0/3), MetricFrechet: 5.0 / 6, MetricHausdorff: 5.0 / 6, MetricEDR: 1.0 / 3, MetricLCS: 1.0 / 3,
+		}},
+		// 每个点重复一次：规整类度量不受影响，匹配类度量按较长的轨迹计
+		{"repeated points", base, line(0, 0, 10, 10, 20, 20), map[string]float64{
+			MetricDTW: 0, MetricFrechet: 0, MetricHausdorff: 0, MetricEDR: 0.5, MetricLCS: 0.5,
+		}},
+		// 反向行驶：Hausdorff 不考虑顺序，其他度量只有中间点匹配
+		{"reversed", base, line(20, 10, 0), map[string]float64{
+			MetricDTW: 40.0 / 43, MetricFrechet: 20.0 / 21, MetricHausdorff: 0, MetricEDR: 2.0 / 3, MetricLCS: 2.0 / 3,
+		}},
+		{"one empty", base, nil, map[string]float64{
+			MetricDTW: 1, MetricFrechet: 1, MetricHausdorff: 1, MetricEDR: 1, MetricLCS: 1,
+		}},
+		{"both empty", nil, nil, map[string]float64{
+			MetricDTW: 0, MetricFrechet: 0, MetricHausdorff: 0, MetricEDR: 0, MetricLCS: 0,
+		}},
+	}
+
+	for _, name := range TrajectoryMetricNames() {
+		cfg := testConfig()
+		cfg.TrajectoryMetric = name
+		cfg.LocationThreshold = 1
+		cfg.LateralThreshold = 0
+		metric := NewReputationManager(cfg).trajectoryMetric()
+		if metric.Name() != name {
+			t.Fatalf("metric %q resolved to %q", name, metric.Name())
+		}
+
+		for _, tc := range cases {
+			want, ok := tc.want[name]
+			if !ok {
+				t.Fatalf("%s: no expectation for metric %s", tc.name, name)
+			}
+			if got := metric.Distance(tc.traj1, tc.traj2); !closeTo(got, want) {
+				t.Errorf("%s/%s: distance = %v, want %v", name, tc.name, got, want)
+			}
+			if got := metric.Distance(tc.traj2, tc.traj1); !closeTo(got, want) {
+				t.Errorf("%s/%s (swapped): distance = %v, want %v", name, tc.name, got, want)
+			}
+		}
+	}
+}
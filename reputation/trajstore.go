@@ -0,0 +1,138 @@
+package reputation
+
+import (
+	"sort"
+	"time"
+)
+
+// TrajectoryPoint 带时间戳的轨迹点
+type TrajectoryPoint struct {
+	Vector
+	Time time.Time `json:"time"`
+}
+
+// trajectoryStore 按车辆保存的轨迹，每辆车的轨迹点按时间升序排列
+type trajectoryStore map[string][]TrajectoryPoint
+
+// add 按时间顺序插入轨迹点，同一时间的点保持插入顺序
+func (s trajectoryStore) add(id string, p TrajectoryPoint) {
+	pts := s[id]
+	i := sort.Search(len(pts), func(k int) bool { return pts[k].Time.After(p.Time) })
+	pts = append(pts, TrajectoryPoint{})
+	copy(pts[i+1:], pts[i:])
+	pts[i] = p
+	s[id] = pts
+}
+
+// window 返回车辆在 [from, to] 内的轨迹点
+func (s trajectoryStore) window(id string, from, to time.Time) []TrajectoryPoint {
+	pts := s[id]
+	lo := sort.Search(len(pts), func(k int) bool { return !pts[k].Time.Before(from) })
+	hi := sort.Search(len(pts), func(k int) bool { return pts[k].Time.After(to) })
+	if lo >= hi {
+		return nil
+	}
+	return pts[lo:hi]
+}
+
+// prune 丢弃车辆早于 before 的轨迹点
+func (s trajectoryStore) prune(id string, before time.Time) {
+	pts := s[id]
+	k := sort.Search(len(pts), func(k int) bool { return !pts[k].Time.Before(before) })
+	if k > 0 {
+		s[id] = append([]TrajectoryPoint{}, pts[k:]...)
+	}
+}
+
+// clone 返回轨迹的副本，没有轨迹时返回 nil
+func (s trajectoryStore) clone() trajectoryStore {
+	if len(s) == 0 {
+		return nil
+	}
+	out := make(trajectoryStore, len(s))
+	for id, pts := range s {
+		out[id] = append([]TrajectoryPoint{}, pts...)
+	}
+	return out
+}
+
+// points 返回保存的轨迹点总数
+func (s trajectoryStore) points() int {
+	var n int
+	for _, pts := range s {
+		n += len(pts)
+	}
+	return n
+}
+
+// RecordTrajectory 记录车辆 id 在 ts 时刻的轨迹点。
+// 配置了 TrajectoryWindow 时只保留窗口内的轨迹点，否则按 RetentionMaxAge 丢弃过期的轨迹点
+func (rm *ReputationManager) RecordTrajectory(id string, ts time.Time, v Vector) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.trajectories.add(id, TrajectoryPoint{Vector: v, Time: ts})
+
+	keep := rm.cfg.TrajectoryWindow
+	if keep <= 0 {
+		keep = rm.cfg.RetentionMaxAge
+	}
+	if keep > 0 {
+		rm.trajectories.prune(id, ts.Add(-time.Duration(keep*float64(time.Second))))
+	}
+}
+
+// TrajectoryWindow 返回车辆 id 在 [from, to] 内的轨迹点
+func (rm *ReputationManager) TrajectoryWindow(id string, from, to time.Time) []TrajectoryPoint {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return append([]TrajectoryPoint{}, rm.trajectories.window(id, from, to)...)
+}
+
+// pairTrajectories 返回计算 SIM(L_from, L_to) 所用的两条轨迹：
+// 配置了 TrajectoryWindow 时使用以 now 结束的时间对齐窗口，窗口内缺少任一车辆的轨迹点时
+// 没有可比较的轨迹（SIM = 0），不使用过时的轨迹；未配置窗口时使用两者首次交互时记录的轨迹。
+// 调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) pairTrajectories(from, to string, now time.Time) (trajFrom, trajTo []Vector) {
+	if rm.cfg.TrajectoryWindow > 0 {
+		return rm.alignedTrajectories(from, to, now)
+	}
+	if pe := rm.store.firstPair(from, to); pe != nil {
+		return pe.trajUser, pe.trajProvider
+	}
+	return nil, nil
+}
+
+// alignedTrajectories 取两辆车在 [now-TrajectoryWindow, now] 内的轨迹，
+// 按时间对齐：a 的每个点与 b 在时间上最接近的点配对，返回等长的两条轨迹。
+// 调用方需持有 rm.mu 的读锁
+func (rm *ReputationManager) alignedTrajectories(a, b string, now time.Time) ([]Vector, []Vector) {
+	if rm.cfg.TrajectoryWindow <= 0 {
+		return nil, nil
+	}
+	from := now.Add(-time.Duration(rm.cfg.TrajectoryWindow * float64(time.Second)))
+	pa := rm.trajectories.window(a, from, now)
+	pb := rm.trajectories.window(b, from, now)
+	if len(pa) == 0 || len(pb) == 0 {
+		return nil, nil
+	}
+
+	trajA := make([]Vector, len(pa))
+	trajB := make([]Vector, len(pa))
+	j := 0
+	for i, p := range pa {
+		// 两条轨迹都按时间升序，最近点的下标单调不减
+		for j+1 < len(pb) && absDuration(pb[j+1].Time.Sub(p.Time)) <= absDuration(pb[j].Time.Sub(p.Time)) {
+			j++
+		}
+		trajA[i] = p.Vector
+		trajB[i] = pb[j].Vector
+	}
+	return trajA, trajB
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,50 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPairTrajectoriesEmptyWindow 配置了窗口但窗口内没有轨迹时，不回退到首次交互的轨迹
+func TestPairTrajectoriesEmptyWindow(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	cfg := testConfig()
+	cfg.TrajectoryWindow = 60
+	rm := NewReputationManager(cfg)
+
+	rm.AddInteraction(Interaction{From: "a", To: "b", PosEvents: 1, Timestamp: now, CommQuality: 0.9,
+		TrajUser: line(0, 10), TrajProvider: line(0, 10)})
+	rm.RecordTrajectory("a", now, Vector{Location: 0})
+	rm.RecordTrajectory("b", now, Vector{Location: 0})
+
+	if trajA, trajB := rm.pairTrajectories("a", "b", now.Add(30*time.Second)); len(trajA) != 1 || len(trajB) != 1 {
+		t.Errorf("inside the window: got %d and %d points, want 1 and 1", len(trajA), len(trajB))
+	}
+	later := now.Add(time.Hour)
+	if trajA, trajB := rm.pairTrajectories("a", "b", later); trajA != nil || trajB != nil {
+		t.Errorf("empty window fell back to stale trajectories %v, %v", trajA, trajB)
+	}
+	if _, _, sim := rm.computeWeight("a", "b", nil, nil, later); sim != 0 {
+		t.Errorf("SIM with no trajectories = %v, want 0", sim)
+	}
+}
+
+// TestRecordTrajectoryPrunesToWindow 未配置 RetentionMaxAge 时同样只保留窗口内的轨迹点
+func TestRecordTrajectoryPrunesToWindow(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	cfg := testConfig()
+	cfg.TrajectoryWindow = 60
+	cfg.RetentionMaxAge = 0
+	rm := NewReputationManager(cfg)
+
+	for k := 0; k < 100; k++ {
+		rm.RecordTrajectory("a", now.Add(time.Duration(k)*10*time.Second), Vector{Location: float64(k)})
+	}
+	pts := rm.TrajectoryWindow("a", time.Time{}, now.Add(time.Hour))
+	if len(pts) != 7 {
+		t.Fatalf("kept %d points, want the 7 within the last 60s", len(pts))
+	}
+	if first := pts[0].Time; !first.Equal(now.Add(930 * time.Second)) {
+		t.Errorf("oldest kept point at %v, want %v", first, now.Add(930*time.Second))
+	}
+}
@@ -0,0 +1,174 @@
+package reputation
+
+import (
+	"sort"
+	"time"
+)
+
+// TrustPath 一条传递信任路径及沿路径折扣后的意见
+type TrustPath struct {
+	Hops    []string `json:"hops"` // 从推荐者到目标的节点序列
+	Opinion Opinion  `json:"opinion"`
+}
+
+// discountOpinion 主观逻辑折扣算子 ω_A:B ⊗ ω_B:x（偏向不确定性）：
+// b = b_A×b_B，d = b_A×d_B，u = d_A + u_A + b_A×u_B，a = a_B
+func discountOpinion(trust, op Opinion) Opinion {
+	return Opinion{
+		Belief:      trust.Belief * op.Belief,
+		Disbelief:   trust.Belief * op.Disbelief,
+		Uncertainty: trust.Disbelief + trust.Uncertainty + trust.Belief*op.Uncertainty,
+		BaseRate:    op.BaseRate,
+	}
+}
+
+// TransitiveTrust 沿邻居图寻找 selfID 到 target 的信任路径，最多经过 cfg.MaxHops 个推荐者，
+// 返回各条路径以及用累积融合合并后的意见。selfID 必须是 rm 所属节点的 ID。
+func (rm *ReputationManager) TransitiveTrust(selfID, target string, now time.Time) ([]TrustPath, Opinion) {
+	// selfID 本身不计入推荐者数量
+	rec, err := rm.Recommend(RecommendationQuery{
+		Recommender: selfID,
+		Target:      target,
+		Hops:        rm.maxHops() + 1,
+		Visited:     []string{selfID, target},
+		Now:         now,
+	})
+	if err != nil || !rec.Found() {
+		rm.mu.RLock()
+		defer rm.mu.RUnlock()
+		return nil, Opinion{Belief: 0, Disbelief: 0, Uncertainty: 1, BaseRate: rm.baseRate(target)}
+	}
+	if len(rec.Paths) == 0 {
+		return []TrustPath{{Hops: []string{selfID, target}, Opinion: rec.Opinion}}, rec.Opinion
+	}
+	return rec.Paths, rec.Opinion
+}
+
+// defaultMaxFanout 每一跳默认最多询问的邻居数量
+const defaultMaxFanout = 3
+
+// nextHop 传递推荐的一个候选中间节点
+type nextHop struct {
+	id    string
+	peer  RecommendationSource
+	trust Opinion // 本节点对中间节点的直接意见（不区分上下文）
+	delta float64 // 本节点到中间节点的整合权重 δ
+}
+
+// nextHops 返回本跳要询问的中间节点：不在当前路径上且本节点有其直接证据，
+// 按信任值从高到低最多取 cfg.MaxFanout 个，使每次查询的推荐请求数不超过 MaxFanout^Hops
+func (rm *ReputationManager) nextHops(q RecommendationQuery, visited map[string]bool) []nextHop {
+	rm.mu.RLock()
+	var hops []nextHop
+	var scores []float64
+	for _, id := range rm.peerIDsLocked() {
+		peer := rm.peers[id]
+		if visited[id] || peer == nil || len(rm.store.byTo[id]) == 0 {
+			continue
+		}
+		trust := rm.computeDirectOpinion(id, ContextAny, q.Now)
+		userTraj, peerTraj := rm.pairTrajectories(q.Recommender, id, q.Now)
+		delta, _, _ := rm.computeWeight(q.Recommender, id, userTraj, peerTraj, q.Now)
+		hops = append(hops, nextHop{id: id, peer: peer, trust: trust, delta: delta})
+		scores = append(scores, trust.ProjectedProbability())
+	}
+	rm.mu.RUnlock()
+
+	// 信任值相同时保持 ID 顺序
+	order := make([]int, len(hops))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+
+	fanout := rm.cfg.MaxFanout
+	if fanout <= 0 {
+		fanout = defaultMaxFanout
+	}
+	if len(order) > fanout {
+		order = order[:fanout]
+	}
+	out := make([]nextHop, len(order))
+	for k, i := range order {
+		out[k] = hops[i]
+	}
+	return out
+}
+
+// maxHops 返回推荐链的最大长度，未配置时为 1（只使用邻居的直接证据）
+func (rm *ReputationManager) maxHops() int {
+	if rm.cfg.MaxHops < 1 {
+		return 1
+	}
+	return rm.cfg.MaxHops
+}
+
+// Recommend 实现 RecommendationSource：返回本节点（q.Recommender）对目标的意见。
+// 有直接证据时直接使用；否则在 q.Hops 允许的范围内递归询问邻居，
+// 用本节点对邻居的直接意见折扣邻居的意见，多条路径用累积融合合并。
+// 每一跳只询问最受信任的 cfg.MaxFanout 个邻居。
+// q.Visited 记录当前路径上的节点，用于检测环路；传递意见的权重取到各第一跳邻居的平均 δ。
+func (rm *ReputationManager) Recommend(q RecommendationQuery) (Recommendation, error) {
+	rec := rm.directRecommendation(q.Recommender, q.Target, q.Context, q.Now)
+	if rec.Interactions > 0 || q.Hops <= 1 || q.Recommender == q.Target {
+		return rec, nil
+	}
+
+	visited := make(map[string]bool, len(q.Visited)+1)
+	for _, id := range q.Visited {
+		visited[id] = true
+	}
+	visited[q.Recommender] = true
+
+	var fused Opinion
+	var paths []TrustPath
+	var weightSum float64
+	var firstHops int
+	for _, hop := range rm.nextHops(q, visited) {
+		id, peer, trustInPeer, delta := hop.id, hop.peer, hop.trust, hop.delta
+
+		sub, err := peer.Recommend(RecommendationQuery{
+			Recommender: id,
+			Target:      q.Target,
+			Context:     q.Context,
+			Hops:        q.Hops - 1,
+			Visited:     append(append([]string{}, q.Visited...), q.Recommender),
+			Now:         q.Now,
+		})
+		if err != nil {
+			rm.logf("获取邻居 %s 的推荐失败: %v", id, err)
+			continue
+		}
+		if !sub.Found() {
+			continue
+		}
+
+		subPaths := sub.Paths
+		if len(subPaths) == 0 {
+			subPaths = []TrustPath{{Hops: []string{id, q.Target}, Opinion: sub.Opinion}}
+		}
+		for _, p := range subPaths {
+			paths = append(paths, TrustPath{
+				Hops:    append([]string{q.Recommender}, p.Hops...),
+				Opinion: discountOpinion(trustInPeer, p.Opinion),
+			})
+		}
+
+		discounted := discountOpinion(trustInPeer, sub.Opinion)
+		if firstHops == 0 {
+			fused = discounted
+		} else {
+			// 不同路径视为相互独立的证据来源
+			fused = cumulativeFusion(fused, discounted)
+		}
+		weightSum += delta
+		firstHops++
+	}
+
+	if firstHops > 0 {
+		rec.Opinion = fused
+		rec.Weight = weightSum / float64(firstHops)
+		rec.Paths = paths
+	}
+	return rec, nil
+}
@@ -0,0 +1,64 @@
+package reputation
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingSource 统计推荐请求次数
+type countingSource struct {
+	src   RecommendationSource
+	calls *atomic.Int64
+}
+
+func (c countingSource) Recommend(q RecommendationQuery) (Recommendation, error) {
+	c.calls.Add(1)
+	return c.src.Recommend(q)
+}
+
+// TestTransitiveFanoutBounded 全连接的邻居图中，每跳只询问 MaxFanout 个最受信任的邻居
+func TestTransitiveFanoutBounded(t *testing.T) {
+	const nodes = 6
+	now := time.Unix(1_000_000, 0)
+	cfg := testConfig()
+	cfg.MaxHops = 3
+	cfg.MaxFanout = 2
+
+	ids := make([]string, nodes)
+	managers := make([]*ReputationManager, nodes)
+	for i := range managers {
+		ids[i] = fmt.Sprintf("v%d", i)
+		managers[i] = NewReputationManager(cfg)
+	}
+	var calls atomic.Int64
+	for i, rm := range managers {
+		for j := range managers {
+			if i == j {
+				continue
+			}
+			rm.AddPeer(ids[j], countingSource{managers[j], &calls})
+			// v5 最受信任，其余邻居的信任值依次降低
+			pos := 1 + j
+			rm.AddInteraction(Interaction{From: ids[i], To: ids[j], PosEvents: pos, NegEvents: nodes - j, Timestamp: now, CommQuality: 0.9})
+		}
+	}
+	// 只有 v5 有目标的直接证据
+	managers[5].AddInteraction(Interaction{From: ids[5], To: "target", PosEvents: 5, Timestamp: now, CommQuality: 0.9})
+
+	paths, op := managers[0].TransitiveTrust(ids[0], "target", now)
+	if len(paths) == 0 || op.Belief == 0 {
+		t.Fatalf("no path to target via the most trusted peer: paths=%v opinion=%+v", paths, op)
+	}
+	for _, p := range paths {
+		if p.Hops[1] != ids[5] && p.Hops[1] != ids[4] {
+			t.Errorf("path %v starts with a peer outside the top %d", p.Hops, cfg.MaxFanout)
+		}
+	}
+
+	// 每跳最多 2 个邻居：2 + 2×2 + 2×2×2
+	if got, limit := calls.Load(), int64(2+4+8); got > limit {
+		t.Errorf("%d recommendation requests, want at most %d", got, limit)
+	}
+}